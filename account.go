@@ -0,0 +1,175 @@
+package skalinsdk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Account is a customer with its contacts and agreements
+type Account struct {
+	Customer   *Customer
+	Contacts   []Contact
+	Agreements []Agreement
+}
+
+type CreateAccountOptions struct {
+	// Compensate deletes the entities created by CreateAccount when a later step fails.
+	// The customer, contacts and agreements are saved by refId, so an entity whose refId existed before the call
+	// is updated by the call and left out of the compensation. Each refId is looked up before its entity is saved
+	Compensate bool
+}
+
+var DefaultCreateAccountOptions = CreateAccountOptions{}
+
+// AccountError is returned by CreateAccount when a step fails
+type AccountError struct {
+	Step  string // customer, contact or agreement
+	Index int    // index of the failed contact or agreement
+	Err   error
+	// Compensated is true if all the entities created before the failure were deleted
+	Compensated        bool
+	CompensationErrors []error
+}
+
+func (e *AccountError) Error() string {
+	message := fmt.Sprintf("error to create %v", e.Step)
+	if e.Step != "customer" {
+		message = fmt.Sprintf("%v %v", message, e.Index)
+	}
+	message = fmt.Sprintf("%v: %v", message, e.Err)
+	if len(e.CompensationErrors) > 0 {
+		messages := make([]string, len(e.CompensationErrors))
+		for i, err := range e.CompensationErrors {
+			messages[i] = err.Error()
+		}
+		message = fmt.Sprintf("%v; compensation failed: %v", message, strings.Join(messages, "; "))
+	}
+	return message
+}
+
+func (e *AccountError) Unwrap() error {
+	return e.Err
+}
+
+func CreateAccount(s Skalin, customer Customer, contacts []Contact, agreements []Agreement) (*Account, error) {
+	return CreateAccountWithOptions(s, customer, contacts, agreements, DefaultCreateAccountOptions)
+}
+
+// CreateAccountWithOptions saves the customer, then creates the contacts and the agreements for the new customer id, in order.
+// It stops at the first failed step and returns the entities created so far with an *AccountError;
+// with compensation, the returned account is empty if the created entities were all deleted
+func CreateAccountWithOptions(s Skalin, customer Customer, contacts []Contact, agreements []Agreement, opts CreateAccountOptions) (*Account, error) {
+	account := &Account{Contacts: make([]Contact, 0, len(contacts)), Agreements: make([]Agreement, 0, len(agreements))}
+	// the existing entities are updated by the saves, so they must not be deleted by the compensation
+	existed := &existingEntities{}
+	if opts.Compensate {
+		var err error
+		if existed.customer, err = existsByRefId(s.GetCustomers, customer.RefId); err != nil {
+			return account, &AccountError{Step: "customer", Err: fmt.Errorf("error to get existing customer: %w", err)}
+		}
+	}
+
+	savedCustomer, err := s.SaveCustomer(customer)
+	if err == nil && (savedCustomer == nil || savedCustomer.Id == "") {
+		err = errors.New("customer saved without id")
+	}
+	if err != nil {
+		return account, &AccountError{Step: "customer", Err: err}
+	}
+	account.Customer = savedCustomer
+
+	fail := func(step string, index int, err error) (*Account, error) {
+		accountErr := &AccountError{Step: step, Index: index, Err: err}
+		if opts.Compensate {
+			accountErr.CompensationErrors = compensate(s, account, existed)
+			accountErr.Compensated = len(accountErr.CompensationErrors) == 0
+			if accountErr.Compensated {
+				account = &Account{Contacts: make([]Contact, 0), Agreements: make([]Agreement, 0)}
+			}
+		}
+		return account, accountErr
+	}
+	for i, contact := range contacts {
+		if opts.Compensate {
+			exists, err := existsByRefId(s.GetContacts, contact.RefId)
+			if err != nil {
+				return fail("contact", i, fmt.Errorf("error to get existing contact: %w", err))
+			}
+			existed.contacts = append(existed.contacts, exists)
+		}
+		created, err := s.CreateContactForCustomer(contact, savedCustomer.Id)
+		if err == nil && (created == nil || created.Id == "") {
+			err = errors.New("contact created without id")
+		}
+		if err != nil {
+			return fail("contact", i, err)
+		}
+		account.Contacts = append(account.Contacts, *created)
+	}
+	for i, agreement := range agreements {
+		if opts.Compensate {
+			exists, err := existsByRefId(s.GetAgreements, agreement.RefId)
+			if err != nil {
+				return fail("agreement", i, fmt.Errorf("error to get existing agreement: %w", err))
+			}
+			existed.agreements = append(existed.agreements, exists)
+		}
+		created, err := s.CreateAgreementForCustomer(agreement, savedCustomer.Id)
+		if err == nil && (created == nil || created.Id == "") {
+			err = errors.New("agreement created without id")
+		}
+		if err != nil {
+			return fail("agreement", i, err)
+		}
+		account.Agreements = append(account.Agreements, *created)
+	}
+	return account, nil
+}
+
+// existingEntities tells which entities of the account had their refId before CreateAccount, by index
+type existingEntities struct {
+	customer   bool
+	contacts   []bool
+	agreements []bool
+}
+
+// existsByRefId returns true if an entity has the refId, an entity without refId is always created
+func existsByRefId[T any](get func(*GetParams) ([]T, error), refId string) (bool, error) {
+	if refId == "" {
+		return false, nil
+	}
+	existing, err := get(&GetParams{Filters: map[string]interface{}{"refId": refId}})
+	if err != nil {
+		return false, err
+	}
+	return len(existing) > 0, nil
+}
+
+// compensate deletes the created entities in the reverse order of their creation and returns the errors,
+// the entities which existed before are kept
+func compensate(s Skalin, account *Account, existed *existingEntities) []error {
+	errs := make([]error, 0)
+	for i := len(account.Agreements) - 1; i >= 0; i-- {
+		if existed.agreements[i] {
+			continue
+		}
+		if err := s.DeleteAgreement(account.Agreements[i]); err != nil {
+			errs = append(errs, fmt.Errorf("error to delete agreement %v: %w", account.Agreements[i].Id, err))
+		}
+	}
+	for i := len(account.Contacts) - 1; i >= 0; i-- {
+		if existed.contacts[i] {
+			continue
+		}
+		if err := s.DeleteContact(account.Contacts[i]); err != nil {
+			errs = append(errs, fmt.Errorf("error to delete contact %v: %w", account.Contacts[i].Id, err))
+		}
+	}
+	if !existed.customer {
+		if err := s.DeleteCustomer(*account.Customer); err != nil {
+			errs = append(errs, fmt.Errorf("error to delete customer %v: %w", account.Customer.Id, err))
+		}
+	}
+	return errs
+}
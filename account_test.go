@@ -0,0 +1,146 @@
+package skalinsdk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateAccount(t *testing.T) {
+	customer := Customer{RefId: "1", Name: "Karnott"}
+	contacts := []Contact{{RefId: "c1"}, {RefId: "c2"}}
+	agreements := []Agreement{{RefId: "a1"}}
+
+	t.Run("OK", func(t *testing.T) {
+		mockSkalin := NewMockSkalin(t)
+		mockSkalin.ExpectSaveCustomer(customer).Return(&Customer{Id: "10", RefId: "1"}, nil).Once()
+		mockSkalin.ExpectCreateContactForCustomer(contacts[0], "10").Return(&Contact{Id: "11", RefId: "c1"}, nil).Once()
+		mockSkalin.ExpectCreateContactForCustomer(contacts[1], "10").Return(&Contact{Id: "12", RefId: "c2"}, nil).Once()
+		mockSkalin.ExpectCreateAgreementForCustomer(agreements[0], "10").Return(&Agreement{Id: "13", RefId: "a1"}, nil).Once()
+
+		account, err := CreateAccount(mockSkalin, customer, contacts, agreements)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "10", account.Customer.Id)
+		assert.Equal(t, []Contact{{Id: "11", RefId: "c1"}, {Id: "12", RefId: "c2"}}, account.Contacts)
+		assert.Equal(t, []Agreement{{Id: "13", RefId: "a1"}}, account.Agreements)
+	})
+
+	t.Run("With compensation", func(t *testing.T) {
+		mockSkalin := NewMockSkalin(t)
+		mockSkalin.ExpectGetCustomers(mock.Anything).Return([]Customer{}, nil).Once()
+		mockSkalin.ExpectSaveCustomer(customer).Return(&Customer{Id: "10", RefId: "1"}, nil).Once()
+		mockSkalin.ExpectGetContacts(mock.Anything).Return([]Contact{}, nil).Twice()
+		mockSkalin.ExpectCreateContactForCustomer(contacts[0], "10").Return(&Contact{Id: "11"}, nil).Once()
+		mockSkalin.ExpectCreateContactForCustomer(contacts[1], "10").Return(&Contact{Id: "12"}, nil).Once()
+		mockSkalin.ExpectGetAgreements(mock.Anything).Return([]Agreement{}, nil).Once()
+		mockSkalin.ExpectCreateAgreementForCustomer(agreements[0], "10").Return(nil, errors.New("startDate is required")).Once()
+		mockSkalin.ExpectDeleteContact(Contact{Id: "12"}).Return(nil).Once()
+		mockSkalin.ExpectDeleteContact(Contact{Id: "11"}).Return(nil).Once()
+		mockSkalin.ExpectDeleteCustomer(Customer{Id: "10", RefId: "1"}).Return(nil).Once()
+
+		account, err := CreateAccountWithOptions(mockSkalin, customer, contacts, agreements, CreateAccountOptions{Compensate: true})
+		var accountErr *AccountError
+		if !assert.True(t, errors.As(err, &accountErr)) {
+			return
+		}
+		assert.EqualError(t, err, "error to create agreement 0: startDate is required")
+		assert.True(t, accountErr.Compensated)
+		assert.Nil(t, account.Customer)
+		assert.Empty(t, account.Contacts)
+	})
+
+	t.Run("Existing customer", func(t *testing.T) {
+		mockSkalin := NewMockSkalin(t)
+		mockSkalin.ExpectGetCustomers(mock.Anything).Return([]Customer{{Id: "10", RefId: "1"}}, nil).Once()
+		mockSkalin.ExpectSaveCustomer(customer).Return(&Customer{Id: "10", RefId: "1"}, nil).Once()
+		mockSkalin.ExpectGetContacts(mock.Anything).Return([]Contact{}, nil).Once()
+		mockSkalin.ExpectCreateContactForCustomer(contacts[0], "10").Return(nil, errors.New("email is invalid")).Once()
+
+		// the customer was updated, it is not deleted
+		_, err := CreateAccountWithOptions(mockSkalin, customer, contacts, agreements, CreateAccountOptions{Compensate: true})
+		var accountErr *AccountError
+		if !assert.True(t, errors.As(err, &accountErr)) {
+			return
+		}
+		assert.True(t, accountErr.Compensated)
+	})
+
+	t.Run("Existing contact", func(t *testing.T) {
+		byRefId := func(refId string) *GetParams {
+			return &GetParams{Filters: map[string]interface{}{"refId": refId}}
+		}
+		mockSkalin := NewMockSkalin(t)
+		mockSkalin.ExpectGetCustomers(byRefId("1")).Return([]Customer{}, nil).Once()
+		mockSkalin.ExpectSaveCustomer(customer).Return(&Customer{Id: "10", RefId: "1"}, nil).Once()
+		mockSkalin.ExpectGetContacts(byRefId("c1")).Return([]Contact{{Id: "11", RefId: "c1"}}, nil).Once()
+		mockSkalin.ExpectCreateContactForCustomer(contacts[0], "10").Return(&Contact{Id: "11", RefId: "c1"}, nil).Once()
+		mockSkalin.ExpectGetContacts(byRefId("c2")).Return([]Contact{}, nil).Once()
+		mockSkalin.ExpectCreateContactForCustomer(contacts[1], "10").Return(&Contact{Id: "12", RefId: "c2"}, nil).Once()
+		mockSkalin.ExpectGetAgreements(byRefId("a1")).Return(nil, errors.New("unreachable")).Once()
+		// the contact c1 was updated, it is not deleted
+		mockSkalin.ExpectDeleteContact(Contact{Id: "12", RefId: "c2"}).Return(nil).Once()
+		mockSkalin.ExpectDeleteCustomer(Customer{Id: "10", RefId: "1"}).Return(nil).Once()
+
+		_, err := CreateAccountWithOptions(mockSkalin, customer, contacts, agreements, CreateAccountOptions{Compensate: true})
+		var accountErr *AccountError
+		if !assert.True(t, errors.As(err, &accountErr)) {
+			return
+		}
+		assert.EqualError(t, err, "error to create agreement 0: error to get existing agreement: unreachable")
+		assert.True(t, accountErr.Compensated)
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		mockSkalin := NewMockSkalin(t)
+		mockSkalin.ExpectSaveCustomer(customer).Return(&Customer{Id: "10", RefId: "1"}, nil).Once()
+		mockSkalin.ExpectCreateContactForCustomer(contacts[0], "10").Return(&Contact{Id: "11"}, nil).Once()
+		mockSkalin.ExpectCreateContactForCustomer(contacts[1], "10").Return(nil, errors.New("email is invalid")).Once()
+
+		// without compensation, the created entities are returned
+		account, err := CreateAccount(mockSkalin, customer, contacts, agreements)
+		assert.EqualError(t, err, "error to create contact 1: email is invalid")
+		assert.Equal(t, "10", account.Customer.Id)
+		assert.Len(t, account.Contacts, 1)
+
+		mockSkalin = NewMockSkalin(t)
+		mockSkalin.ExpectGetCustomers(mock.Anything).Return([]Customer{}, nil).Once()
+		mockSkalin.ExpectSaveCustomer(customer).Return(&Customer{Id: "10", RefId: "1"}, nil).Once()
+		mockSkalin.ExpectGetContacts(mock.Anything).Return([]Contact{}, nil).Once()
+		mockSkalin.ExpectCreateContactForCustomer(contacts[0], "10").Return(nil, errors.New("email is invalid")).Once()
+		mockSkalin.ExpectDeleteCustomer(mock.Anything).Return(errors.New("unreachable")).Once()
+
+		account, err = CreateAccountWithOptions(mockSkalin, customer, contacts, agreements, CreateAccountOptions{Compensate: true})
+		var accountErr *AccountError
+		if !assert.True(t, errors.As(err, &accountErr)) {
+			return
+		}
+		assert.False(t, accountErr.Compensated)
+		assert.EqualError(t, err, "error to create contact 0: email is invalid; compensation failed: error to delete customer 10: unreachable")
+		assert.Equal(t, "10", account.Customer.Id)
+
+		// a created entity without result triggers the compensation
+		mockSkalin = NewMockSkalin(t)
+		mockSkalin.ExpectGetCustomers(mock.Anything).Return([]Customer{}, nil).Once()
+		mockSkalin.ExpectSaveCustomer(customer).Return(&Customer{Id: "10", RefId: "1"}, nil).Once()
+		mockSkalin.ExpectGetContacts(mock.Anything).Return([]Contact{}, nil).Twice()
+		mockSkalin.ExpectCreateContactForCustomer(contacts[0], "10").Return(&Contact{Id: "11"}, nil).Once()
+		mockSkalin.ExpectCreateContactForCustomer(contacts[1], "10").Return(nil, nil).Once()
+		mockSkalin.ExpectDeleteContact(Contact{Id: "11"}).Return(nil).Once()
+		mockSkalin.ExpectDeleteCustomer(Customer{Id: "10", RefId: "1"}).Return(nil).Once()
+		account, err = CreateAccountWithOptions(mockSkalin, customer, contacts, agreements, CreateAccountOptions{Compensate: true})
+		if assert.True(t, errors.As(err, &accountErr)) {
+			assert.EqualError(t, err, "error to create contact 1: contact created without id")
+			assert.True(t, accountErr.Compensated)
+			assert.Nil(t, account.Customer)
+		}
+
+		mockSkalin = NewMockSkalin(t)
+		mockSkalin.ExpectSaveCustomer(customer).Return(nil, errors.New("name is required")).Once()
+		_, err = CreateAccount(mockSkalin, customer, contacts, agreements)
+		assert.EqualError(t, err, "error to create customer: name is required")
+	})
+}
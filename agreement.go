@@ -6,22 +6,22 @@ import (
 )
 
 type Agreement struct {
-	Id               string      `json:"id,omitempty"`
-	CustomerId       *string     `json:"customerId,omitempty"` // correspond to the customer Id
-	Customer         *string     `json:"customer,omitempty"`   // correspond to the customer refId
-	RefId            string      `json:"refId,omitempty"`
-	StartDate        *SkalinDate `json:"startDate,omitempty"`   // need to be at format `YYYY-MM-DD`
-	EndDate          *SkalinDate `json:"endDate,omitempty"`     // need to be at format `YYYY-MM-DD`
-	RenewalDate      *SkalinDate `json:"renewalDate,omitempty"` // need to be at format `YYYY-MM-DD`
-	AutoRenew        bool        `json:"autoRenew,omitempty"`
-	Engagement       *int        `json:"engagement,omitempty"` // need pointer because engagement value can be 0
-	EngagementPeriod string      `json:"engagementPeriod,omitempty"`
-	Notice           *int        `json:"notice,omitempty"` // need pointer because engagement value can be 0
-	NoticePeriod     string      `json:"noticePeriod,omitempty"`
-	Plan             string      `json:"plan,omitempty"`
-	Type             string      `json:"type,omitempty"`
-	Mrr              *int        `json:"mrr,omitempty"`
-	Fee              *int        `json:"fee,omitempty"`
+	Id               string        `json:"id,omitempty"`
+	CustomerId       *string       `json:"customerId,omitempty"` // correspond to the customer Id
+	Customer         *string       `json:"customer,omitempty"`   // correspond to the customer refId
+	RefId            string        `json:"refId,omitempty"`
+	StartDate        *SkalinDate   `json:"startDate,omitempty"`   // need to be at format `YYYY-MM-DD`
+	EndDate          *SkalinDate   `json:"endDate,omitempty"`     // need to be at format `YYYY-MM-DD`
+	RenewalDate      *SkalinDate   `json:"renewalDate,omitempty"` // need to be at format `YYYY-MM-DD`
+	AutoRenew        bool          `json:"autoRenew,omitempty"`
+	Engagement       *int          `json:"engagement,omitempty"` // need pointer because engagement value can be 0
+	EngagementPeriod Period        `json:"engagementPeriod,omitempty"`
+	Notice           *int          `json:"notice,omitempty"` // need pointer because engagement value can be 0
+	NoticePeriod     Period        `json:"noticePeriod,omitempty"`
+	Plan             string        `json:"plan,omitempty"`
+	Type             AgreementType `json:"type,omitempty"`
+	Mrr              *Money        `json:"mrr,omitempty"` // in the account currency, see WithAccountCurrency
+	Fee              *Money        `json:"fee,omitempty"`
 }
 
 const (
@@ -52,6 +52,11 @@ func (s *skalinAPI) GetAgreements(params *GetParams) ([]Agreement, error) {
 	return getEntities[[]Agreement](s, SAVE_AGREEMENT_PATH, buildQueryParamsFromGetParams(params))
 }
 
+// WalkAgreements calls fn with the agreements of each page, it stops at the first error returned by fn
+func (s *skalinAPI) WalkAgreements(params *GetParams, fn func([]Agreement) error) error {
+	return walkEntities[[]Agreement](s, SAVE_AGREEMENT_PATH, buildQueryParamsFromGetParams(params), fn)
+}
+
 func (s *skalinAPI) CreateAgreementForCustomer(agreement Agreement, customerId string) (*Agreement, error) {
 	return save(s, fmt.Sprintf(CREATE_CUSTOMER_AGREEMENT_PATH, customerId), agreement)
 }
@@ -61,10 +66,10 @@ func (s *skalinAPI) DeleteAgreement(agreement Agreement) error {
 		return fmt.Errorf("agreement id is empty")
 	}
 	// for now the API does not return the updated agreement
-	r, b, err := s.api.DeleteData(BuildUrl(fmt.Sprintf(UPDATE_AGREEMENT_PATH, agreement.Id)), "", nil, nil, nil, http.StatusOK)
-	s.api.GetLogger().Infof("Delete agreement %+v: %v", r, string(b))
+	_, _, err := s.api.DeleteData(BuildUrl(fmt.Sprintf(UPDATE_AGREEMENT_PATH, agreement.Id)), "", nil, nil, nil, http.StatusOK)
 	if err != nil {
 		return err
 	}
+	loggerOf(s.api).Log(LevelDebug, "agreement deleted", Fields{"id": agreement.Id})
 	return nil
 }
@@ -167,7 +167,8 @@ func TestSaveAgreement(t *testing.T) {
 		assert.Equal(t, expectedAgreement.EngagementPeriod, agreement.EngagementPeriod)
 		assert.Equal(t, expectedAgreement.Plan, agreement.Plan)
 		assert.Equal(t, expectedAgreement.Mrr, agreement.Mrr)
-		assert.Equal(t, expectedAgreement.Fee, agreement.Fee)
+		// the amounts read have the account currency
+		assert.Equal(t, NewMoney(10, CurrencyEUR), *agreement.Fee)
 	})
 
 	t.Run("With error", func(t *testing.T) {
@@ -266,7 +267,8 @@ func TestCreateAgreementForCustomer(t *testing.T) {
 		assert.Equal(t, expectedAgreement.EngagementPeriod, agreement.EngagementPeriod)
 		assert.Equal(t, expectedAgreement.Plan, agreement.Plan)
 		assert.Equal(t, expectedAgreement.Mrr, agreement.Mrr)
-		assert.Equal(t, expectedAgreement.Fee, agreement.Fee)
+		// the amounts read have the account currency
+		assert.Equal(t, NewMoney(10, CurrencyEUR), *agreement.Fee)
 	})
 
 	t.Run("With error", func(t *testing.T) {
@@ -0,0 +1,322 @@
+// Package agreements interprets the dates and periods of the Skalin agreements:
+// renewal dates, notice deadlines, activity at a date and annual recurring revenue.
+//
+// An agreement runs by terms of Engagement x EngagementPeriod from its StartDate.
+// The terms are computed from the start date, so an agreement starting a 31st renews on the last day
+// of the shorter months without drifting (Jan 31, Feb 28, Mar 31...). All the dates are compared as dates, without time
+package agreements
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+)
+
+type Period = skalinsdk.Period
+
+const (
+	Day   = skalinsdk.PeriodDay
+	Week  = skalinsdk.PeriodWeek
+	Month = skalinsdk.PeriodMonth
+	Year  = skalinsdk.PeriodYear
+)
+
+var (
+	ErrNoStartDate  = errors.New("agreement has no start date")
+	ErrNoEngagement = errors.New("agreement has no engagement")
+	// ErrNoRenewal is returned by NextRenewal for an agreement which does not renew anymore
+	ErrNoRenewal = errors.New("agreement is not renewed")
+)
+
+// ParsePeriod returns the period of a Skalin period unit, case insensitive
+func ParsePeriod(s string) (Period, error) {
+	switch p := Period(strings.ToUpper(strings.TrimSpace(s))); p {
+	case Day, Week, Month, Year:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown period %q", s)
+}
+
+// AddPeriods adds n periods (n can be negative) to the date of t.
+// Months and years are clamped to the last day of the month: Jan 31 + 1 month is Feb 28 (or 29)
+func AddPeriods(t time.Time, n int, p Period) time.Time {
+	t = Date(t)
+	switch p {
+	case Day:
+		return t.AddDate(0, 0, n)
+	case Week:
+		return t.AddDate(0, 0, 7*n)
+	case Year:
+		n *= 12
+	}
+	year, month, day := t.Date()
+	// the first day of the target month, normalized by time.Date
+	first := time.Date(year, month+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	if last := daysIn(first.Year(), first.Month()); day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Date returns the date of t at midnight UTC, the time of the Skalin dates
+func Date(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func skalinDate(d *skalinsdk.SkalinDate) *time.Time {
+	if d == nil {
+		return nil
+	}
+	t := Date(time.Time(*d))
+	return &t
+}
+
+// Engagement returns the duration of a term of the agreement
+func Engagement(a skalinsdk.Agreement) (int, Period, error) {
+	if a.Engagement == nil || *a.Engagement <= 0 {
+		return 0, "", ErrNoEngagement
+	}
+	period, err := ParsePeriod(string(a.EngagementPeriod))
+	if err != nil {
+		return 0, "", fmt.Errorf("engagement period: %w", err)
+	}
+	return *a.Engagement, period, nil
+}
+
+// TermEnd returns the end of the term running at the date: the first date of the next term.
+// Before the start date, it is the end of the first term
+func TermEnd(a skalinsdk.Agreement, at time.Time) (time.Time, error) {
+	start := skalinDate(a.StartDate)
+	if start == nil {
+		return time.Time{}, ErrNoStartDate
+	}
+	n, period, err := Engagement(a)
+	if err != nil {
+		return time.Time{}, err
+	}
+	at = Date(at)
+	// starts from an estimation of the number of terms, then moves to the first term end after the date
+	k := estimateTerms(*start, at, n, period)
+	for k > 1 && AddPeriods(*start, (k-1)*n, period).After(at) {
+		k--
+	}
+	for !AddPeriods(*start, k*n, period).After(at) {
+		k++
+	}
+	return AddPeriods(*start, k*n, period), nil
+}
+
+// estimateTerms returns an approximate number of terms between start and at, at least 1
+func estimateTerms(start, at time.Time, n int, period Period) int {
+	days := int(at.Sub(start).Hours() / 24)
+	termDays := map[Period]int{Day: 1, Week: 7, Month: 28, Year: 365}[period] * n
+	if days <= 0 || termDays == 0 {
+		return 1
+	}
+	return days/termDays + 1
+}
+
+// NextRenewal returns the date of the next renewal after the date, which is the end of the running term.
+// It returns ErrNoRenewal if the agreement is not auto renewed or if the renewal is after its end date
+func NextRenewal(a skalinsdk.Agreement, at time.Time) (time.Time, error) {
+	if !a.AutoRenew {
+		return time.Time{}, ErrNoRenewal
+	}
+	renewal, err := TermEnd(a, at)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end := skalinDate(a.EndDate); end != nil && renewal.After(*end) {
+		return time.Time{}, ErrNoRenewal
+	}
+	return renewal, nil
+}
+
+// NoticeDeadline returns the last date to give notice before the next renewal:
+// the next renewal minus Notice x NoticePeriod, or the next renewal without notice
+func NoticeDeadline(a skalinsdk.Agreement, at time.Time) (time.Time, error) {
+	renewal, err := NextRenewal(a, at)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if a.Notice == nil || *a.Notice == 0 {
+		return renewal, nil
+	}
+	period, err := ParsePeriod(string(a.NoticePeriod))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("notice period: %w", err)
+	}
+	return AddPeriods(renewal, -*a.Notice, period), nil
+}
+
+// IsActive returns true if the agreement runs at the date: after its start date and before its end date (included).
+// Without end date, an agreement which is not auto renewed ends with its first term
+func IsActive(a skalinsdk.Agreement, at time.Time) bool {
+	at = Date(at)
+	start := skalinDate(a.StartDate)
+	if start == nil || at.Before(*start) {
+		return false
+	}
+	if end := skalinDate(a.EndDate); end != nil {
+		return !at.After(*end)
+	}
+	if a.AutoRenew {
+		return true
+	}
+	n, period, err := Engagement(a)
+	if err != nil {
+		// without engagement, the agreement has no known end
+		return true
+	}
+	return at.Before(AddPeriods(*start, n, period))
+}
+
+// ARR returns the annual recurring revenue of the agreement: 12 x Mrr, or the Fee of a term annualized
+// when the agreement has no Mrr. It returns zero without currency without Mrr nor Fee
+func ARR(a skalinsdk.Agreement) (skalinsdk.Money, error) {
+	if a.Mrr != nil {
+		return a.Mrr.Mul(12), nil
+	}
+	if a.Fee == nil {
+		return skalinsdk.Money{}, nil
+	}
+	n, period, err := Engagement(a)
+	if err != nil {
+		return skalinsdk.Money{}, fmt.Errorf("fee without engagement: %w", err)
+	}
+	switch period {
+	case Month:
+		return a.Fee.Mul(12).Div(int64(n))
+	case Year:
+		return a.Fee.Div(int64(n))
+	}
+	return skalinsdk.Money{}, fmt.Errorf("fee can't be annualized with a %v engagement", period)
+}
+
+// MRR returns the monthly recurring revenue of the agreement: its Mrr, or the Fee of a term by month
+// when the agreement has no Mrr. It returns zero without currency without Mrr nor Fee
+func MRR(a skalinsdk.Agreement) (skalinsdk.Money, error) {
+	if a.Mrr != nil {
+		return *a.Mrr, nil
+	}
+	if a.Fee == nil {
+		return skalinsdk.Money{}, nil
+	}
+	n, period, err := Engagement(a)
+	if err != nil {
+		return skalinsdk.Money{}, fmt.Errorf("fee without engagement: %w", err)
+	}
+	switch period {
+	case Month:
+		return a.Fee.Div(int64(n))
+	case Year:
+		return a.Fee.Div(int64(12 * n))
+	}
+	return skalinsdk.Money{}, fmt.Errorf("fee can't be counted by month with a %v engagement", period)
+}
+
+// TotalMRR returns the sum of the MRR of the agreements active at the date, like the agreements of a customer
+func TotalMRR(agreements []skalinsdk.Agreement, at time.Time) (skalinsdk.Money, error) {
+	total := skalinsdk.Money{}
+	for _, a := range agreements {
+		if !IsActive(a, at) {
+			continue
+		}
+		mrr, err := MRR(a)
+		if err == nil {
+			total, err = total.Add(mrr)
+		}
+		if err != nil {
+			return skalinsdk.Money{}, fmt.Errorf("error to compute mrr of agreement %v: %w", a.RefId, err)
+		}
+	}
+	return total, nil
+}
+
+// MRRByCustomer returns the MRR of the agreements active at the date by customer,
+// keyed by the customer id of the agreement, or by the customer refId without id
+func MRRByCustomer(agreements []skalinsdk.Agreement, at time.Time) (map[string]skalinsdk.Money, error) {
+	byCustomer := make(map[string][]skalinsdk.Agreement)
+	for _, a := range agreements {
+		customer := ""
+		if a.CustomerId != nil {
+			customer = *a.CustomerId
+		} else if a.Customer != nil {
+			customer = *a.Customer
+		}
+		byCustomer[customer] = append(byCustomer[customer], a)
+	}
+	mrr := make(map[string]skalinsdk.Money, len(byCustomer))
+	for customer, list := range byCustomer {
+		total, err := TotalMRR(list, at)
+		if err != nil {
+			return nil, fmt.Errorf("error to compute mrr of customer %v: %w", customer, err)
+		}
+		mrr[customer] = total
+	}
+	return mrr, nil
+}
+
+// ValidatePeriods checks that the periods of the agreement are consistent: a duration has a known unit and the reverse,
+// the notice is shorter than the engagement, and the end and renewal dates are after the start date
+func ValidatePeriods(a skalinsdk.Agreement) error {
+	errs := make([]error, 0)
+	engagement, engagementErr := validateDuration("engagement", a.Engagement, a.EngagementPeriod)
+	if engagementErr != nil {
+		errs = append(errs, engagementErr)
+	}
+	notice, noticeErr := validateDuration("notice", a.Notice, a.NoticePeriod)
+	if noticeErr != nil {
+		errs = append(errs, noticeErr)
+	}
+	if engagementErr == nil && noticeErr == nil && engagement != nil && notice != nil {
+		// compares the durations from a reference date, a month being between 28 and 31 days
+		reference := time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+		if AddPeriods(reference, notice.n, notice.period).After(AddPeriods(reference, engagement.n, engagement.period)) {
+			errs = append(errs, fmt.Errorf("notice of %v %v is longer than the engagement of %v %v", notice.n, notice.period, engagement.n, engagement.period))
+		}
+	}
+	if start := skalinDate(a.StartDate); start != nil {
+		if end := skalinDate(a.EndDate); end != nil && end.Before(*start) {
+			errs = append(errs, errors.New("end date is before the start date"))
+		}
+		if renewal := skalinDate(a.RenewalDate); renewal != nil && renewal.Before(*start) {
+			errs = append(errs, errors.New("renewal date is before the start date"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type duration struct {
+	n      int
+	period Period
+}
+
+// validateDuration returns nil without error if the duration is not set
+func validateDuration(name string, n *int, unit Period) (*duration, error) {
+	if n == nil && unit == "" {
+		return nil, nil
+	}
+	if n == nil {
+		return nil, fmt.Errorf("%v period %v is set without %v", name, unit, name)
+	}
+	if *n < 0 {
+		return nil, fmt.Errorf("%v must be positive", name)
+	}
+	if unit == "" {
+		return nil, fmt.Errorf("%v is set without period", name)
+	}
+	period, err := ParsePeriod(string(unit))
+	if err != nil {
+		return nil, fmt.Errorf("%v period: %w", name, err)
+	}
+	return &duration{n: *n, period: period}, nil
+}
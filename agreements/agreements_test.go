@@ -0,0 +1,343 @@
+package agreements
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func skalinDateOf(s string) *skalinsdk.SkalinDate {
+	d := skalinsdk.SkalinDate(date(s))
+	return &d
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// agreement returns an auto renewed agreement starting at start, by terms of n periods
+func agreement(start string, n int, period Period) skalinsdk.Agreement {
+	return skalinsdk.Agreement{
+		StartDate:        skalinDateOf(start),
+		AutoRenew:        true,
+		Engagement:       intPtr(n),
+		EngagementPeriod: period,
+	}
+}
+
+func TestAddPeriods(t *testing.T) {
+	tests := []struct {
+		name     string
+		date     string
+		n        int
+		period   Period
+		expected string
+	}{
+		{"Days", "2023-12-30", 3, Day, "2024-01-02"},
+		{"Weeks", "2024-02-22", 1, Week, "2024-02-29"},
+		{"Month", "2023-01-15", 1, Month, "2023-02-15"},
+		{"End of January", "2023-01-31", 1, Month, "2023-02-28"},
+		{"End of January of a leap year", "2024-01-31", 1, Month, "2024-02-29"},
+		{"Two months from the 31st", "2023-01-31", 2, Month, "2023-03-31"},
+		{"30 days month", "2023-03-31", 1, Month, "2023-04-30"},
+		{"Over a year", "2023-11-30", 3, Month, "2024-02-29"},
+		{"Negative months", "2024-03-31", -1, Month, "2024-02-29"},
+		{"Negative months over a year", "2024-01-31", -2, Month, "2023-11-30"},
+		{"Year", "2023-06-15", 1, Year, "2024-06-15"},
+		{"Leap day", "2024-02-29", 1, Year, "2025-02-28"},
+		{"Leap day after 4 years", "2024-02-29", 4, Year, "2028-02-29"},
+		{"Negative year from leap day", "2024-02-29", -1, Year, "2023-02-28"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, date(tt.expected), AddPeriods(date(tt.date), tt.n, tt.period))
+		})
+	}
+
+	t.Run("Time is ignored", func(t *testing.T) {
+		at := time.Date(2023, time.January, 31, 23, 59, 0, 0, time.FixedZone("UTC+2", 2*3600))
+		assert.Equal(t, date("2023-02-28"), AddPeriods(at, 1, Month))
+	})
+}
+
+func TestParsePeriod(t *testing.T) {
+	period, err := ParsePeriod(" month ")
+	assert.NoError(t, err)
+	assert.Equal(t, Month, period)
+	_, err = ParsePeriod("QUARTER")
+	assert.Error(t, err)
+}
+
+func TestNextRenewal(t *testing.T) {
+	tests := []struct {
+		name      string
+		agreement skalinsdk.Agreement
+		at        string
+		expected  string
+		err       error
+	}{
+		{"Before the start", agreement("2023-01-31", 1, Month), "2022-12-01", "2023-02-28", nil},
+		{"First term", agreement("2023-01-31", 1, Month), "2023-02-10", "2023-02-28", nil},
+		{"On the renewal date", agreement("2023-01-31", 1, Month), "2023-02-28", "2023-03-31", nil},
+		{"Month end without drift", agreement("2023-01-31", 1, Month), "2023-04-01", "2023-04-30", nil},
+		{"Month end of a leap year", agreement("2023-01-31", 1, Month), "2024-02-01", "2024-02-29", nil},
+		{"Quarters", agreement("2023-11-30", 3, Month), "2023-12-01", "2024-02-29", nil},
+		{"Leap day yearly", agreement("2024-02-29", 1, Year), "2025-01-01", "2025-02-28", nil},
+		{"Leap day after 4 years", agreement("2024-02-29", 1, Year), "2027-03-01", "2028-02-29", nil},
+		{"Long running", agreement("2001-01-31", 1, Month), "2023-06-15", "2023-06-30", nil},
+		{"Weeks", agreement("2023-01-02", 2, Week), "2023-01-16", "2023-01-30", nil},
+		{"Not auto renewed", func() skalinsdk.Agreement {
+			a := agreement("2023-01-01", 1, Year)
+			a.AutoRenew = false
+			return a
+		}(), "2023-06-01", "", ErrNoRenewal},
+		{"After the end date", func() skalinsdk.Agreement {
+			a := agreement("2023-01-01", 1, Year)
+			a.EndDate = skalinDateOf("2023-12-31")
+			return a
+		}(), "2023-06-01", "", ErrNoRenewal},
+		{"Without start date", skalinsdk.Agreement{AutoRenew: true, Engagement: intPtr(1), EngagementPeriod: "YEAR"}, "2023-06-01", "", ErrNoStartDate},
+		{"Without engagement", skalinsdk.Agreement{AutoRenew: true, StartDate: skalinDateOf("2023-01-01")}, "2023-06-01", "", ErrNoEngagement},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renewal, err := NextRenewal(tt.agreement, date(tt.at))
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err), "error %v", err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, date(tt.expected), renewal)
+			}
+		})
+	}
+}
+
+func TestNoticeDeadline(t *testing.T) {
+	withNotice := func(a skalinsdk.Agreement, n int, period Period) skalinsdk.Agreement {
+		a.Notice = intPtr(n)
+		a.NoticePeriod = period
+		return a
+	}
+	tests := []struct {
+		name      string
+		agreement skalinsdk.Agreement
+		at        string
+		expected  string
+	}{
+		{"Without notice", agreement("2023-01-01", 1, Year), "2023-06-01", "2024-01-01"},
+		{"Month of notice", withNotice(agreement("2023-03-31", 1, Year), 1, Month), "2023-06-01", "2024-02-29"},
+		{"Days of notice", withNotice(agreement("2023-01-01", 1, Year), 30, Day), "2023-06-01", "2023-12-02"},
+		{"Deadline passed", withNotice(agreement("2023-01-01", 1, Year), 3, Month), "2023-11-15", "2023-10-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadline, err := NoticeDeadline(tt.agreement, date(tt.at))
+			if assert.NoError(t, err) {
+				assert.Equal(t, date(tt.expected), deadline)
+			}
+		})
+	}
+
+	t.Run("With error", func(t *testing.T) {
+		_, err := NoticeDeadline(withNotice(agreement("2023-01-01", 1, Year), 1, "QUARTER"), date("2023-06-01"))
+		assert.Error(t, err)
+	})
+}
+
+func TestIsActive(t *testing.T) {
+	notRenewed := agreement("2023-01-31", 1, Month)
+	notRenewed.AutoRenew = false
+	withEnd := agreement("2023-01-01", 1, Year)
+	withEnd.EndDate = skalinDateOf("2023-12-31")
+	tests := []struct {
+		name      string
+		agreement skalinsdk.Agreement
+		at        time.Time
+		expected  bool
+	}{
+		{"Before the start", withEnd, date("2022-12-31"), false},
+		{"Start date", withEnd, date("2023-01-01"), true},
+		{"End date", withEnd, time.Date(2023, time.December, 31, 23, 0, 0, 0, time.UTC), true},
+		{"After the end date", withEnd, date("2024-01-01"), false},
+		{"Auto renewed", agreement("2023-01-01", 1, Month), date("2030-01-01"), true},
+		{"Last day of the first term", notRenewed, date("2023-02-27"), true},
+		{"End of the first term", notRenewed, date("2023-02-28"), false},
+		{"Without start date", skalinsdk.Agreement{}, date("2023-01-01"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsActive(tt.agreement, tt.at))
+		})
+	}
+}
+
+func eur(amount string) *skalinsdk.Money {
+	m, err := skalinsdk.ParseMoney(amount, skalinsdk.CurrencyEUR)
+	if err != nil {
+		panic(err)
+	}
+	return &m
+}
+
+func TestARR(t *testing.T) {
+	withFee := func(a skalinsdk.Agreement, fee string) skalinsdk.Agreement {
+		a.Fee = eur(fee)
+		return a
+	}
+	tests := []struct {
+		name      string
+		agreement skalinsdk.Agreement
+		expected  string
+		err       bool
+	}{
+		{"Mrr", skalinsdk.Agreement{Mrr: eur("100"), Fee: eur("5000")}, "1200 EUR", false},
+		{"Mrr with cents", skalinsdk.Agreement{Mrr: eur("99.99")}, "1199.88 EUR", false},
+		{"Monthly fee", withFee(agreement("2023-01-01", 1, Month), "100"), "1200 EUR", false},
+		{"Quarterly fee", withFee(agreement("2023-01-01", 3, Month), "300"), "1200 EUR", false},
+		{"Yearly fee", withFee(agreement("2023-01-01", 1, Year), "1200"), "1200 EUR", false},
+		{"Two years fee", withFee(agreement("2023-01-01", 2, Year), "2400"), "1200 EUR", false},
+		// zero without currency
+		{"Without amount", agreement("2023-01-01", 1, Year), "0", false},
+		{"Weekly fee", withFee(agreement("2023-01-01", 1, Week), "100"), "", true},
+		{"Fee without engagement", skalinsdk.Agreement{Fee: eur("100")}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arr, err := ARR(tt.agreement)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, arr.String())
+			}
+		})
+	}
+}
+
+func TestMRR(t *testing.T) {
+	withFee := func(a skalinsdk.Agreement, fee string) skalinsdk.Agreement {
+		a.Fee = eur(fee)
+		return a
+	}
+	tests := []struct {
+		name      string
+		agreement skalinsdk.Agreement
+		expected  string
+	}{
+		{"Mrr", skalinsdk.Agreement{Mrr: eur("99.90"), Fee: eur("5000")}, "99.90 EUR"},
+		{"Quarterly fee", withFee(agreement("2023-01-01", 3, Month), "300"), "100 EUR"},
+		{"Yearly fee truncated to the cent", withFee(agreement("2023-01-01", 1, Year), "1000"), "83.33 EUR"},
+		// zero without currency
+		{"Without amount", agreement("2023-01-01", 1, Year), "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mrr, err := MRR(tt.agreement)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, mrr.String())
+			}
+		})
+	}
+}
+
+func TestMRRByCustomer(t *testing.T) {
+	customer := func(a skalinsdk.Agreement, id string, mrr string) skalinsdk.Agreement {
+		a.CustomerId = &id
+		a.Mrr = eur(mrr)
+		return a
+	}
+	ended := customer(agreement("2022-01-01", 1, Year), "c1", "1000")
+	ended.EndDate = skalinDateOf("2022-12-31")
+	list := []skalinsdk.Agreement{
+		customer(agreement("2023-01-01", 1, Year), "c1", "99.90"),
+		customer(agreement("2023-06-01", 1, Month), "c1", "10.15"),
+		ended,
+		customer(agreement("2023-01-01", 1, Year), "c2", "50"),
+		// not started yet
+		customer(agreement("2024-01-01", 1, Year), "c2", "50"),
+	}
+	at := date("2023-07-01")
+
+	t.Run("OK", func(t *testing.T) {
+		total, err := TotalMRR(list, at)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "160.05 EUR", total.String())
+		}
+		byCustomer, err := MRRByCustomer(list, at)
+		if assert.NoError(t, err) {
+			assert.Equal(t, map[string]skalinsdk.Money{"c1": *eur("110.05"), "c2": *eur("50")}, byCustomer)
+		}
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		usd := skalinsdk.NewMoney(10, skalinsdk.CurrencyUSD)
+		withUSD := customer(agreement("2023-01-01", 1, Year), "c1", "0")
+		withUSD.Mrr = &usd
+		_, err := TotalMRR(append(list, withUSD), at)
+		assert.True(t, errors.Is(err, skalinsdk.ErrCurrencyMismatch), "error %v", err)
+	})
+}
+
+func TestValidatePeriods(t *testing.T) {
+	valid := agreement("2023-01-01", 1, Year)
+	valid.Notice = intPtr(3)
+	valid.NoticePeriod = "month"
+	valid.EndDate = skalinDateOf("2025-12-31")
+	tests := []struct {
+		name      string
+		agreement func(a skalinsdk.Agreement) skalinsdk.Agreement
+		expected  string
+	}{
+		{"OK", func(a skalinsdk.Agreement) skalinsdk.Agreement { return a }, ""},
+		{"Without periods", func(a skalinsdk.Agreement) skalinsdk.Agreement { return skalinsdk.Agreement{} }, ""},
+		{"Unknown period", func(a skalinsdk.Agreement) skalinsdk.Agreement {
+			a.EngagementPeriod = "QUARTER"
+			return a
+		}, `engagement period: unknown period "QUARTER"`},
+		{"Period without duration", func(a skalinsdk.Agreement) skalinsdk.Agreement {
+			a.Notice = nil
+			return a
+		}, "notice period month is set without notice"},
+		{"Duration without period", func(a skalinsdk.Agreement) skalinsdk.Agreement {
+			a.EngagementPeriod = ""
+			return a
+		}, "engagement is set without period"},
+		{"Notice longer than the engagement", func(a skalinsdk.Agreement) skalinsdk.Agreement {
+			a.Notice = intPtr(13)
+			return a
+		}, "notice of 13 MONTH is longer than the engagement of 1 YEAR"},
+		{"Notice of a month in a 4 weeks engagement", func(a skalinsdk.Agreement) skalinsdk.Agreement {
+			a.Engagement, a.EngagementPeriod = intPtr(4), "WEEK"
+			a.Notice = intPtr(1)
+			return a
+		}, "notice of 1 MONTH is longer than the engagement of 4 WEEK"},
+		{"Dates", func(a skalinsdk.Agreement) skalinsdk.Agreement {
+			a.EndDate = skalinDateOf("2022-12-31")
+			a.RenewalDate = skalinDateOf("2022-12-31")
+			return a
+		}, "end date is before the start date\nrenewal date is before the start date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePeriods(tt.agreement(valid))
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
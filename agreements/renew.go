@@ -0,0 +1,201 @@
+package agreements
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/internal/records"
+)
+
+type Format = records.Format
+
+const (
+	FormatCSV   = records.FormatCSV
+	FormatJSONL = records.FormatJSONL
+)
+
+type RenewOutcome string
+
+const (
+	RenewOutcomeRenewed RenewOutcome = "renewed"
+	RenewOutcomeDryRun  RenewOutcome = "dry-run" // renewal computed but not sent
+	RenewOutcomeFailed  RenewOutcome = "failed"
+)
+
+type RenewOptions struct {
+	At     time.Time // date of the run, today if zero
+	DryRun bool      // computes the renewals without updating the agreements
+}
+
+// Renewal is the change of the dates of an agreement
+type Renewal struct {
+	ID             string
+	RefID          string
+	RenewalDate    time.Time
+	NewRenewalDate time.Time
+	EndDate        *time.Time
+	NewEndDate     *time.Time
+	Outcome        RenewOutcome
+	Err            error
+}
+
+type RenewReport struct {
+	Scanned  int
+	Due      int // auto renewed agreements with a passed renewal date, not after their end date
+	Renewed  int
+	Failed   int
+	Renewals []Renewal
+}
+
+// Renew rolls forward the renewal date of the auto renewed agreements whose renewal date is passed (or is today),
+// to the end of the term running at the date of the run. As in NextRenewal, the terms are counted from the start date,
+// so the renewals don't drift on the shorter months. The end date, if any, is moved by the same number of terms.
+// An agreement whose renewal date is after its end date has ended and is not renewed.
+// As the renewed agreements have a renewal date in the future, a second run on the same day changes nothing.
+// An agreement which can't be renewed (no start date, no engagement, update failed) is reported as failed and the others are still renewed
+func Renew(client skalinsdk.Skalin, opts RenewOptions) (*RenewReport, error) {
+	at := opts.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	at = Date(at)
+	report := &RenewReport{Renewals: make([]Renewal, 0)}
+	err := client.WalkAgreements(nil, func(agreements []skalinsdk.Agreement) error {
+		report.Scanned += len(agreements)
+		for _, agreement := range agreements {
+			renew(client, agreement, at, opts, report)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error to get agreements: %w", err)
+	}
+	return report, nil
+}
+
+// renew renews the agreement if it is due and adds the renewal to the report
+func renew(client skalinsdk.Skalin, agreement skalinsdk.Agreement, at time.Time, opts RenewOptions, report *RenewReport) {
+	renewalDate := skalinDate(agreement.RenewalDate)
+	if !agreement.AutoRenew || renewalDate == nil || renewalDate.After(at) {
+		return
+	}
+	if end := skalinDate(agreement.EndDate); end != nil && renewalDate.After(*end) {
+		return
+	}
+	report.Due++
+	renewal := Renewal{
+		ID:          agreement.Id,
+		RefID:       agreement.RefId,
+		RenewalDate: *renewalDate,
+		EndDate:     skalinDate(agreement.EndDate),
+	}
+	renewal.Err = nextDates(agreement, at, &renewal)
+	if renewal.Err == nil && !opts.DryRun {
+		_, renewal.Err = client.UpdateAgreement(renewal.update())
+	}
+	switch {
+	case renewal.Err != nil:
+		renewal.Outcome = RenewOutcomeFailed
+		report.Failed++
+	case opts.DryRun:
+		renewal.Outcome = RenewOutcomeDryRun
+	default:
+		renewal.Outcome = RenewOutcomeRenewed
+		report.Renewed++
+	}
+	report.Renewals = append(report.Renewals, renewal)
+}
+
+// nextDates sets the first renewal date after at, the end of the running term. The end date is moved by the terms
+// between the current and the new renewal dates, and keeps its number of days in its term
+func nextDates(agreement skalinsdk.Agreement, at time.Time, renewal *Renewal) error {
+	next, err := TermEnd(agreement, at)
+	if err != nil {
+		return err
+	}
+	renewal.NewRenewalDate = next
+	if renewal.EndDate != nil {
+		// TermEnd checked the start date and the engagement
+		start := *skalinDate(agreement.StartDate)
+		n, period, _ := Engagement(agreement)
+		terms, _ := termAt(start, next, n, period)
+		current, _ := termAt(start, renewal.RenewalDate, n, period)
+		endTerm, endTermStart := termAt(start, *renewal.EndDate, n, period)
+		newEnd := AddPeriods(start, (endTerm+terms-current)*n, period).Add(renewal.EndDate.Sub(endTermStart))
+		renewal.NewEndDate = &newEnd
+	}
+	return nil
+}
+
+// termAt returns the number of terms from the start to the term running at t, and the first date of this term
+func termAt(start, t time.Time, n int, period Period) (int, time.Time) {
+	k := estimateTerms(start, t, n, period)
+	for k > 0 && AddPeriods(start, k*n, period).After(t) {
+		k--
+	}
+	for !AddPeriods(start, (k+1)*n, period).After(t) {
+		k++
+	}
+	return k, AddPeriods(start, k*n, period)
+}
+
+// update returns the agreement with only the changed fields, the other fields are kept by Skalin
+func (r Renewal) update() skalinsdk.Agreement {
+	renewalDate := skalinsdk.SkalinDate(r.NewRenewalDate)
+	agreement := skalinsdk.Agreement{Id: r.ID, RenewalDate: &renewalDate}
+	if r.NewEndDate != nil {
+		endDate := skalinsdk.SkalinDate(*r.NewEndDate)
+		agreement.EndDate = &endDate
+	}
+	return agreement
+}
+
+// RenewalColumns are the columns of the rows of the report
+var RenewalColumns = []string{"id", "refId", "renewalDate", "newRenewalDate", "endDate", "newEndDate", "outcome", "error"}
+
+// Rows returns the renewals as flat rows, with the dates formatted as YYYY-MM-DD
+func (r *RenewReport) Rows() []map[string]string {
+	formatDate := func(t *time.Time) string {
+		if t == nil || t.IsZero() {
+			return ""
+		}
+		return t.Format(time.DateOnly)
+	}
+	rows := make([]map[string]string, len(r.Renewals))
+	for i, renewal := range r.Renewals {
+		rows[i] = map[string]string{
+			"id":             renewal.ID,
+			"refId":          renewal.RefID,
+			"renewalDate":    formatDate(&renewal.RenewalDate),
+			"newRenewalDate": formatDate(&renewal.NewRenewalDate),
+			"endDate":        formatDate(renewal.EndDate),
+			"newEndDate":     formatDate(renewal.NewEndDate),
+			"outcome":        string(renewal.Outcome),
+		}
+		if renewal.Err != nil {
+			rows[i]["error"] = renewal.Err.Error()
+		}
+	}
+	return rows
+}
+
+// WriteReport writes the rows of the report in CSV or JSON Lines
+func (r *RenewReport) WriteReport(w io.Writer, format Format) error {
+	writer, err := records.NewWriter(w, format, RenewalColumns)
+	if err != nil {
+		return err
+	}
+	for _, row := range r.Rows() {
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("error to write renewal %v: %w", row["id"], err)
+		}
+	}
+	return writer.Flush()
+}
+
+// String summarizes the report, like `3 agreements renewed, 1 failed, 4 due on 42 scanned`
+func (r *RenewReport) String() string {
+	return fmt.Sprintf("%v agreements renewed, %v failed, %v due on %v scanned", r.Renewed, r.Failed, r.Due, r.Scanned)
+}
@@ -0,0 +1,110 @@
+package agreements
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenew(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	client, err := skalinsdk.New("clientId", "clientApiId", "clientApiSecret",
+		skalinsdk.WithHTTPClient(server.Client()),
+		skalinsdk.WithLogger(skalinsdk.NewNopLogger()),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// the entities are replaced by id, so each subtest starts with the same agreements
+	seed := func() {
+		for _, entity := range []skalintest.Entity{
+			// due for more than a year, renewed monthly from the 31st
+			{"id": "a1", "refId": "1", "autoRenew": true, "engagement": 1, "engagementPeriod": "MONTH",
+				"startDate": "2023-01-31", "renewalDate": "2023-03-31", "endDate": "2023-04-30"},
+			// due today
+			{"id": "a2", "refId": "2", "autoRenew": true, "engagement": 1, "engagementPeriod": "YEAR",
+				"startDate": "2023-06-15", "renewalDate": "2024-06-15"},
+			// not due
+			{"id": "a3", "refId": "3", "autoRenew": true, "engagement": 1, "engagementPeriod": "YEAR", "renewalDate": "2024-07-01"},
+			// not auto renewed
+			{"id": "a4", "refId": "4", "engagement": 1, "engagementPeriod": "YEAR", "renewalDate": "2024-01-01"},
+			// due without engagement
+			{"id": "a5", "refId": "5", "autoRenew": true, "startDate": "2023-01-01", "renewalDate": "2024-01-01"},
+			// due, the end date moves by a year, not by 366 days
+			{"id": "a6", "refId": "6", "autoRenew": true, "engagement": 1, "engagementPeriod": "YEAR",
+				"startDate": "2022-06-20", "renewalDate": "2023-06-20", "endDate": "2023-12-31"},
+			// ended before its renewal date
+			{"id": "a7", "refId": "7", "autoRenew": true, "engagement": 1, "engagementPeriod": "MONTH",
+				"renewalDate": "2024-01-31", "endDate": "2024-01-15"},
+		} {
+			_, err := server.Seed(skalintest.Agreements, entity)
+			assert.NoError(t, err)
+		}
+	}
+	at := date("2024-06-15")
+
+	t.Run("OK", func(t *testing.T) {
+		seed()
+		report, err := Renew(client, RenewOptions{At: at})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "3 agreements renewed, 1 failed, 4 due on 7 scanned", report.String())
+		assert.Equal(t, "2024-06-30", server.Entity(skalintest.Agreements, "a1")["renewalDate"])
+		// the terms are counted from Jan 31, so the end of the month is kept
+		assert.Equal(t, "2024-07-31", server.Entity(skalintest.Agreements, "a1")["endDate"])
+		assert.Equal(t, "2024-12-31", server.Entity(skalintest.Agreements, "a6")["endDate"])
+		assert.Equal(t, "2024-01-31", server.Entity(skalintest.Agreements, "a7")["renewalDate"])
+		assert.Equal(t, "2025-06-15", server.Entity(skalintest.Agreements, "a2")["renewalDate"])
+		// the other fields are kept
+		assert.Equal(t, "2023-06-15", server.Entity(skalintest.Agreements, "a2")["startDate"])
+
+		buf := &bytes.Buffer{}
+		assert.NoError(t, report.WriteReport(buf, FormatCSV))
+		assert.Equal(t, "id,refId,renewalDate,newRenewalDate,endDate,newEndDate,outcome,error\n"+
+			"a1,1,2023-03-31,2024-06-30,2023-04-30,2024-07-31,renewed,\n"+
+			"a2,2,2024-06-15,2025-06-15,,,renewed,\n"+
+			"a5,5,2024-01-01,,,,failed,agreement has no engagement\n"+
+			"a6,6,2023-06-20,2024-06-20,2023-12-31,2024-12-31,renewed,\n", buf.String())
+
+		// a second run on the same day changes nothing
+		report, err = Renew(client, RenewOptions{At: at})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 0, report.Renewed)
+		assert.Equal(t, 1, report.Due)
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		seed()
+		report, err := Renew(client, RenewOptions{At: at, DryRun: true})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, RenewOutcomeDryRun, report.Renewals[0].Outcome)
+		assert.Equal(t, date("2024-06-30"), report.Renewals[0].NewRenewalDate)
+		assert.Equal(t, "2023-03-31", server.Entity(skalintest.Agreements, "a1")["renewalDate"])
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		seed()
+		server.InjectFault("PATCH /v1/agreements/a1", skalintest.Fault{Status: http.StatusInternalServerError})
+		defer server.ClearFaults()
+		report, err := Renew(client, RenewOptions{At: at})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, RenewOutcomeFailed, report.Renewals[0].Outcome)
+		assert.Equal(t, 2, report.Renewed)
+
+		server.InjectFault("GET /v1/agreements", skalintest.Fault{Status: http.StatusInternalServerError})
+		_, err = Renew(client, RenewOptions{At: at})
+		assert.Error(t, err)
+	})
+}
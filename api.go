@@ -1,11 +1,10 @@
 package skalinsdk
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -27,24 +26,69 @@ type API interface {
 }
 
 type SkalinAPI struct {
-	clientID *string
-	token    *string
-	logger   *CustomLog
+	clientID            *string
+	token               *string
+	logger              Logger
+	disableLogRedaction bool
+	sdkLogger           Logger // logger used by the SDK, see setupLogger
+	httpClient          *http.Client
+	middlewares         []Middleware
+	replaceMiddlewares  bool
+	listObservers       []ListObserver
+	hitObservers        []HitObserver
+	enumMode            EnumMode
+	customerStages      []CustomerStage
+	currency            Currency
+	customAttributes    []string
+	ctx                 context.Context
 }
 
 func (a *SkalinAPI) SetLogger(logger logrus.FieldLogger) {
-	a.logger = &CustomLog{
-		logger,
-	}
+	a.logger = NewLogrusLogger(logger)
+	a.setupLogger()
 }
 
+// GetLogger returns the logrus logger of the API (Log by default), a logger set with WithLogger is not returned.
+// The SDK logs with the logger of WithLogger if it is set, with the redaction of WithLogRedaction
 func (a *SkalinAPI) GetLogger() *CustomLog {
-	if a.logger != nil {
-		return a.logger
+	if logger, ok := a.logger.(*CustomLog); ok {
+		return logger
 	}
 	return Log
 }
 
+// setupLogger wraps the logger of the options once, so the logs are redacted unless disabled with WithLogRedaction
+func (a *SkalinAPI) setupLogger() {
+	a.sdkLogger = a.newSDKLogger()
+}
+
+func (a *SkalinAPI) newSDKLogger() Logger {
+	var logger Logger = Log
+	if a.logger != nil {
+		logger = a.logger
+	}
+	if a.disableLogRedaction {
+		return logger
+	}
+	return NewRedactingLogger(logger)
+}
+
+// log returns the logger used by the SDK
+func (a *SkalinAPI) log() Logger {
+	if a.sdkLogger != nil {
+		return a.sdkLogger
+	}
+	return a.newSDKLogger()
+}
+
+// loggerOf returns the logger used by the SDK for api, the other implementations of API log with their GetLogger
+func loggerOf(api API) Logger {
+	if a, ok := api.(*SkalinAPI); ok {
+		return a.log()
+	}
+	return api.GetLogger()
+}
+
 func (a *SkalinAPI) GetClientID() *string {
 	return a.clientID
 }
@@ -53,45 +97,21 @@ func (a *SkalinAPI) WithToken(token string) API {
 	a.token = &token
 	return a
 }
+
+// withContext returns a copy of the API whose requests use ctx
+func (a *SkalinAPI) withContext(ctx context.Context) API {
+	c := *a
+	c.ctx = ctx
+	return &c
+}
+
 func (a *SkalinAPI) WithClientID(clientID string) API {
 	a.clientID = &clientID
 	return a
 }
 
-func (a SkalinAPI) doRequest(method, queryUrl, contentType string, extraHeaders map[string][]string, body []byte, queryParams *url.Values) (*http.Response, error) {
-	var bodyReader io.Reader
-	if body != nil {
-		bodyReader = bytes.NewBuffer(body)
-	}
-	req, err := http.NewRequest(method, queryUrl, bodyReader)
-	if err != nil {
-		return nil, err
-	}
-	for key, values := range extraHeaders {
-		for _, value := range values {
-			req.Header.Add(key, value)
-		}
-	}
-	if contentType != "" {
-		req.Header.Set("Content-Type", contentType)
-	}
-	if a.token != nil {
-		req.Header["Authorization"] = []string{"Bearer " + *a.token}
-	}
-	req.Header["Accept-Language"] = []string{"fr"}
-
-	if queryParams == nil {
-		queryParams = &url.Values{}
-	}
-	if a.clientID != nil {
-		queryParams.Set("clientId", *a.clientID)
-	}
-	req.URL.RawQuery = queryParams.Encode()
-	return http.DefaultClient.Do(req)
-}
-
 func (a SkalinAPI) send(method, url, contentType string, extraHeaders map[string][]string, body []byte, queryParams *url.Values, expectedStatusCode int) (*http.Response, []byte, error) {
-	logFields := logrus.Fields{
+	logFields := Fields{
 		"method":             method,
 		"url":                url,
 		"content-type":       contentType,
@@ -100,20 +120,24 @@ func (a SkalinAPI) send(method, url, contentType string, extraHeaders map[string
 	if queryParams != nil {
 		logFields["params"] = queryParams.Encode()
 	}
-	a.GetLogger().WithFields(logFields).Infof("call skalin API")
-	res, err := a.doRequest(
-		method,
-		url,
-		contentType,
-		extraHeaders,
-		body,
-		queryParams,
-	)
+	a.log().Log(LevelDebug, "call skalin API", logFields)
+	req := newRequest(method, url, contentType, extraHeaders, body, queryParams, expectedStatusCode)
+	req.ClientID = a.clientID
+	req.Token = a.token
+	if a.ctx != nil {
+		req.Context = a.ctx
+	}
+	resp, err := a.handler()(req)
+	if err == nil && resp == nil {
+		err = ErrUndefined
+	}
 	if err != nil {
-		a.GetLogger().WithFields(logFields).Errorf("error to call skalin API: %v", err)
+		logFields["error"] = err
+		a.log().Log(LevelError, "error to call skalin API", logFields)
 		return nil, nil, err
 	}
-	bodyResp, err := a.readResp(res, expectedStatusCode)
+	res := resp.httpResponse()
+	bodyResp, err := a.readResp(resp, expectedStatusCode)
 	if err != nil {
 		if body != nil {
 			if strings.Contains(string(body), "access_token") {
@@ -124,7 +148,8 @@ func (a SkalinAPI) send(method, url, contentType string, extraHeaders map[string
 		}
 		logFields["bodyResponse"] = string(bodyResp)
 		logFields["responseStatusCode"] = res.StatusCode
-		a.GetLogger().WithFields(logFields).Errorf("error to read response from skalin API: %v", err)
+		logFields["error"] = err
+		a.log().Log(LevelError, "error to read response from skalin API", logFields)
 	}
 	return res, bodyResp, err
 }
@@ -189,22 +214,34 @@ func (a SkalinAPI) GetData(url, contentType string, extraHeaders map[string][]st
 	)
 }
 
-func (a SkalinAPI) readResp(res *http.Response, expectedStatusCode int) ([]byte, error) {
+func (a SkalinAPI) readResp(res *Response, expectedStatusCode int) ([]byte, error) {
 	if res == nil {
 		return nil, nil
 	}
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
+	body := res.Body
 	if res.StatusCode != expectedStatusCode {
 		errorMessage := a.extractErrorMessage(body)
 		if errorMessage == nil {
 			errorMessage = fmt.Errorf("status code != %v: %v", expectedStatusCode, res.StatusCode)
 		}
-		return body, errorMessage
+		return body, &HTTPError{StatusCode: res.StatusCode, Err: errorMessage}
 	}
-	return body, err
+	return body, nil
+}
+
+// HTTPError is returned when Skalin answers with an unexpected status code.
+// Its message is the error message sent by Skalin
+type HTTPError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *HTTPError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
 }
 
 type SkalinResponseError struct {
@@ -221,7 +258,7 @@ func (a SkalinAPI) extractErrorMessage(body []byte) error {
 	var responseErr SkalinResponseError
 	err := json.Unmarshal(body, &responseErr)
 	if err != nil {
-		a.GetLogger().Infof("error to unmarshal skalin response error: %v", err)
+		a.log().Log(LevelDebug, "skalin response error is not JSON", Fields{"error": err})
 		return errors.New(string(body))
 	}
 	if responseErr.Message != "" {
@@ -2,6 +2,9 @@ package skalinsdk
 
 import (
 	"os"
+	"testing"
+
+	"github.com/karnott/skalin-sdk/skalintest"
 )
 
 func GetSkalinClientApiID() string {
@@ -31,3 +34,13 @@ func GetSkalinExistingCustomerIdForTest() string {
 func GetSkalinExistingContactIdForTest() string {
 	return os.Getenv("TEST_SKALIN_EXISTING_CONTACT_ID")
 }
+
+// newCassetteClient returns a client replaying the cassette testdata/cassettes/<name>.json.
+// With SKALIN_CASSETTE_MODE=record, the cassette is recorded with the credentials of the TEST_SKALIN_* env vars
+func newCassetteClient(t *testing.T, name string) (Skalin, error) {
+	httpClient := skalintest.NewCassette(t, name)
+	if os.Getenv(skalintest.CassetteModeEnv) == string(skalintest.ModeRecord) {
+		return New(GetSkalinAppClientID(), GetSkalinClientApiID(), GetSkalinClientApiSecret(), WithHTTPClient(httpClient))
+	}
+	return New("clientId", "clientApiId", "clientApiSecret", WithHTTPClient(httpClient))
+}
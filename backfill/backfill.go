@@ -0,0 +1,269 @@
+// Package backfill sends historical events, read from CSV or JSON Lines files, to the Skalin tracker.
+//
+// The progress is saved in a checkpoint file after each batch,
+// so an interrupted backfill can be resumed without sending the same event twice.
+package backfill
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/internal/records"
+)
+
+type Format = records.Format
+
+const (
+	FormatCSV   = records.FormatCSV
+	FormatJSONL = records.FormatJSONL
+)
+
+// Mapping gives the column (or JSON key) of the file used for each field of the hit.
+// An empty column means the field is not read from the file
+type Mapping struct {
+	Action        string // if not set or empty, the action is `ev` when an event name is set, `ui` otherwise
+	VisitorID     string
+	VisitID       string
+	IdentityID    string
+	IdentityEmail string
+	Name          string // if not set, the event name is used
+	EventName     string
+	EventID       string
+	CustomerID    string
+	Timestamp     string
+	TimeZone      string // IANA name of the end user timezone, used to compute the local time of the event
+	URL           string
+	// TimestampLayout is the layout used to parse the timestamp column.
+	// `unix` and `unixms` can be used for epoch timestamps.
+	// If empty, RFC3339, `2006-01-02 15:04:05` (UTC) and unix timestamps are tried
+	TimestampLayout string
+}
+
+var DefaultMapping = Mapping{
+	Action:        "action",
+	VisitorID:     "visitor_id",
+	VisitID:       "visit_id",
+	IdentityID:    "identity_id",
+	IdentityEmail: "identity_email",
+	Name:          "name",
+	EventName:     "event_name",
+	EventID:       "event_id",
+	CustomerID:    "customer_id",
+	Timestamp:     "ts",
+	TimeZone:      "timezone",
+	URL:           "url",
+}
+
+type Options struct {
+	Format         Format // if empty, the format is guessed from the extension of the file
+	Mapping        Mapping
+	CheckpointPath string // if empty, the progress is not saved
+	BatchSize      int
+	HitBatch       skalinsdk.HitBatchOptions
+}
+
+var DefaultOptions = Options{
+	Mapping:   DefaultMapping,
+	BatchSize: 100,
+	HitBatch:  skalinsdk.DefaultHitBatchOptions,
+}
+
+type Report struct {
+	Read    int // number of records read in the file
+	Skipped int // records already sent by a previous run
+	Sent    int
+}
+
+// Run reads the events of the source file and sends them with the tracker.
+// It stops at the first invalid record or at the first batch with failed hits;
+// running it again with the same checkpoint resumes where it stopped
+func Run(tracker skalinsdk.SkalinTracking, source string, opts Options) (*Report, error) {
+	format := opts.Format
+	if format == "" {
+		var err error
+		format, err = records.FormatFromPath(source)
+		if err != nil {
+			return nil, err
+		}
+	}
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = DefaultOptions.BatchSize
+	}
+
+	checkpoint, err := loadCheckpoint(opts.CheckpointPath, source)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader, err := records.NewReader(file, format)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	hits := make([]skalinsdk.HitTrack, 0, batchSize)
+	batch := make([]*records.Record, 0, batchSize)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Read++
+		if checkpoint.isSent(record.Line) {
+			report.Skipped++
+			continue
+		}
+		hit, err := opts.Mapping.hitFromRecord(record)
+		if err != nil {
+			return report, fmt.Errorf("line %v: %w", record.FileLine, err)
+		}
+		hits = append(hits, *hit)
+		batch = append(batch, record)
+		if len(hits) < batchSize {
+			continue
+		}
+		if err := sendBatch(tracker, hits, batch, opts, checkpoint, report); err != nil {
+			return report, err
+		}
+		hits = hits[:0]
+		batch = batch[:0]
+	}
+	if len(hits) > 0 {
+		if err := sendBatch(tracker, hits, batch, opts, checkpoint, report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// sendBatch sends the hits read from the records of batch, the checkpoint is kept by record number
+func sendBatch(tracker skalinsdk.SkalinTracking, hits []skalinsdk.HitTrack, batch []*records.Record, opts Options, checkpoint *Checkpoint, report *Report) error {
+	results, batchErr := tracker.HitBatchWithOptions(hits, opts.HitBatch)
+	if results == nil && batchErr != nil {
+		// report the first invalid record with its line in the file
+		var validationErr *skalinsdk.HitValidationError
+		if errors.As(batchErr, &validationErr) {
+			first := -1
+			for index := range validationErr.Errors {
+				if first == -1 || index < first {
+					first = index
+				}
+			}
+			return fmt.Errorf("line %v: %w", batch[first].FileLine, validationErr.Errors[first])
+		}
+		return batchErr
+	}
+	for _, result := range results {
+		if result.Err == nil {
+			checkpoint.markSent(batch[result.Index].Line)
+			report.Sent++
+		}
+	}
+	if err := checkpoint.save(opts.CheckpointPath); err != nil {
+		return err
+	}
+	if batchErr != nil {
+		failed := results.Failed()
+		return fmt.Errorf("line %v: %w", batch[failed[0]].FileLine, results[failed[0]].Err)
+	}
+	return nil
+}
+
+func (m Mapping) hitFromRecord(record *records.Record) (*skalinsdk.HitTrack, error) {
+	field := func(column string) string {
+		if column == "" {
+			return ""
+		}
+		return strings.TrimSpace(record.Fields[column])
+	}
+	optionalField := func(column string) *string {
+		if v := field(column); v != "" {
+			return &v
+		}
+		return nil
+	}
+
+	hit := &skalinsdk.HitTrack{
+		Action:     skalinsdk.HitAction(field(m.Action)),
+		VisitorID:  field(m.VisitorID),
+		VisitID:    field(m.VisitID),
+		EventID:    optionalField(m.EventID),
+		CustomerID: optionalField(m.CustomerID),
+		URL:        optionalField(m.URL),
+		TimeZone:   field(m.TimeZone),
+		Identity: skalinsdk.HitIdentity{
+			ID:    optionalField(m.IdentityID),
+			Email: optionalField(m.IdentityEmail),
+		},
+	}
+	if eventName := field(m.EventName); eventName != "" {
+		name := field(m.Name)
+		if name == "" {
+			name = eventName
+		}
+		hit.Event = &skalinsdk.HitEvent{
+			Name:      name,
+			EventName: eventName,
+		}
+	}
+	if hit.Action == "" {
+		hit.Action = skalinsdk.HitActionUserIdendity
+		if hit.Event != nil {
+			hit.Action = skalinsdk.HitActionEvent
+		}
+	}
+	if ts := field(m.Timestamp); ts != "" {
+		t, err := parseTimestamp(ts, m.TimestampLayout)
+		if err != nil {
+			return nil, err
+		}
+		hit.Ts = &t
+	}
+	return hit, nil
+}
+
+var defaultTimestampLayouts = []string{time.RFC3339Nano, time.DateTime}
+
+func parseTimestamp(value, layout string) (time.Time, error) {
+	switch layout {
+	case "unix", "unixms":
+		epoch, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %v timestamp %q: %w", layout, value, err)
+		}
+		if layout == "unixms" {
+			return time.UnixMilli(epoch).UTC(), nil
+		}
+		return time.Unix(epoch, 0).UTC(), nil
+	case "":
+		for _, l := range defaultTimestampLayouts {
+			if t, err := time.Parse(l, value); err == nil {
+				return t, nil
+			}
+		}
+		if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.Unix(epoch, 0).UTC(), nil
+		}
+		return time.Time{}, fmt.Errorf("unable to parse timestamp %q", value)
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", value, err)
+	}
+	return t, nil
+}
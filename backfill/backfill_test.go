@@ -0,0 +1,132 @@
+package backfill
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTracker records the hits and fails for the visitors listed in failFor
+type fakeTracker struct {
+	hits    []skalinsdk.HitTrack
+	failFor map[string]bool
+}
+
+func (f *fakeTracker) Hit(ht skalinsdk.HitTrack) (*http.Response, []byte, error) {
+	if f.failFor[ht.VisitorID] {
+		return nil, nil, errors.New("collect is unreachable")
+	}
+	f.hits = append(f.hits, ht)
+	return nil, nil, nil
+}
+
+func (f *fakeTracker) HitBatch(hits []skalinsdk.HitTrack) (skalinsdk.HitResults, error) {
+	return f.HitBatchWithOptions(hits, skalinsdk.DefaultHitBatchOptions)
+}
+
+func (f *fakeTracker) HitBatchWithOptions(hits []skalinsdk.HitTrack, _ skalinsdk.HitBatchOptions) (skalinsdk.HitResults, error) {
+	results := make(skalinsdk.HitResults, len(hits))
+	var err error
+	for i, ht := range hits {
+		_, _, results[i].Err = f.Hit(ht)
+		results[i].Index = i
+		if results[i].Err != nil {
+			err = results[i].Err
+		}
+	}
+	return results, err
+}
+
+const testEvents = `visitor_id,visit_id,identity_id,event_name,customer_id,ts,timezone,url
+1111111111111111,aaaaaaaaaaaaaaaa,user1,login,customer1,2023-03-26T01:30:00Z,Europe/Paris,https://app.karnott.fr
+2222222222222222,bbbbbbbbbbbbbbbb,user2,logout,,2023-03-26 03:30:00,,
+3333333333333333,cccccccccccccccc,user3,login,customer3,1679801400,,
+`
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "events.csv")
+	if !assert.NoError(t, os.WriteFile(source, []byte(testEvents), 0o600)) {
+		return
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		tracker := &fakeTracker{}
+		report, err := Run(tracker, source, DefaultOptions)
+		if !assert.NoError(t, err) || !assert.Len(t, tracker.hits, 3) {
+			return
+		}
+		assert.Equal(t, Report{Read: 3, Sent: 3}, *report)
+		hit := tracker.hits[0]
+		assert.Equal(t, skalinsdk.HitActionEvent, hit.Action)
+		assert.Equal(t, "login", hit.Event.EventName)
+		assert.Equal(t, "customer1", *hit.CustomerID)
+		assert.Equal(t, "https://app.karnott.fr", *hit.URL)
+		assert.Equal(t, "Europe/Paris", hit.TimeZone)
+		assert.True(t, time.Date(2023, 3, 26, 1, 30, 0, 0, time.UTC).Equal(*hit.Ts))
+		assert.Nil(t, tracker.hits[1].CustomerID)
+		assert.True(t, time.Date(2023, 3, 26, 3, 30, 0, 0, time.UTC).Equal(*tracker.hits[1].Ts))
+		assert.True(t, time.Unix(1679801400, 0).Equal(*tracker.hits[2].Ts))
+	})
+
+	t.Run("Resume from checkpoint", func(t *testing.T) {
+		opts := DefaultOptions
+		opts.CheckpointPath = filepath.Join(dir, "checkpoint.json")
+		tracker := &fakeTracker{failFor: map[string]bool{"2222222222222222": true}}
+		report, err := Run(tracker, source, opts)
+		if !assert.Error(t, err) {
+			return
+		}
+		// the second record is on the third line of the file, after the header
+		assert.Contains(t, err.Error(), "line 3")
+		assert.Equal(t, 2, report.Sent)
+
+		tracker.failFor = nil
+		tracker.hits = nil
+		report, err = Run(tracker, source, opts)
+		if !assert.NoError(t, err) || !assert.Len(t, tracker.hits, 1) {
+			return
+		}
+		assert.Equal(t, "2222222222222222", tracker.hits[0].VisitorID)
+		assert.Equal(t, Report{Read: 3, Skipped: 2, Sent: 1}, *report)
+	})
+
+	t.Run("Checkpoint with blank lines", func(t *testing.T) {
+		blankSource := filepath.Join(dir, "blank.jsonl")
+		err := os.WriteFile(blankSource, []byte(`{"visitor_id": "1111111111111111", "visit_id": "aaaaaaaaaaaaaaaa", "identity_id": "user1"}`+"\n\n"+
+			`{"visitor_id": "2222222222222222", "visit_id": "bbbbbbbbbbbbbbbb", "identity_id": "user2"}`+"\n"), 0o600)
+		if !assert.NoError(t, err) {
+			return
+		}
+		opts := DefaultOptions
+		opts.CheckpointPath = filepath.Join(dir, "blank-checkpoint.json")
+		_, err = Run(&fakeTracker{}, blankSource, opts)
+		if !assert.NoError(t, err) {
+			return
+		}
+		checkpoint, err := loadCheckpoint(opts.CheckpointPath, blankSource)
+		if assert.NoError(t, err) {
+			// the watermark is not stopped by the blank line
+			assert.Equal(t, 2, checkpoint.Line)
+			assert.Empty(t, checkpoint.Sent)
+		}
+	})
+
+	t.Run("With invalid record", func(t *testing.T) {
+		invalidSource := filepath.Join(dir, "invalid.jsonl")
+		err := os.WriteFile(invalidSource, []byte(`{"visitor_id": "1111111111111111", "visit_id": "aaaaaaaaaaaaaaaa", "identity_id": "user1", "ts": "yesterday"}`), 0o600)
+		if !assert.NoError(t, err) {
+			return
+		}
+		tracker := &fakeTracker{}
+		_, err = Run(tracker, invalidSource, DefaultOptions)
+		assert.ErrorContains(t, err, "line 1")
+		assert.Empty(t, tracker.hits)
+	})
+}
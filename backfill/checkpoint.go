@@ -0,0 +1,88 @@
+package backfill
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Checkpoint is the progress of a backfill, saved as JSON.
+// All the records up to Line have been sent, and also the records listed in Sent
+// (records sent after a failed one in the same batch)
+type Checkpoint struct {
+	Source string `json:"source"`
+	Line   int    `json:"line"`
+	Sent   []int  `json:"sent,omitempty"`
+
+	sent map[int]bool
+}
+
+func loadCheckpoint(path, source string) (*Checkpoint, error) {
+	checkpoint := &Checkpoint{Source: source, sent: make(map[int]bool)}
+	if path == "" {
+		return checkpoint, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, checkpoint); err != nil {
+		return nil, fmt.Errorf("error to unmarshal checkpoint %v: %w", path, err)
+	}
+	if checkpoint.Source != source {
+		return nil, fmt.Errorf("checkpoint %v was created for %v, not for %v", path, checkpoint.Source, source)
+	}
+	for _, line := range checkpoint.Sent {
+		checkpoint.sent[line] = true
+	}
+	return checkpoint, nil
+}
+
+func (c *Checkpoint) isSent(line int) bool {
+	return line <= c.Line || c.sent[line]
+}
+
+func (c *Checkpoint) markSent(line int) {
+	c.sent[line] = true
+	// move the watermark forward while the next lines are sent
+	for c.sent[c.Line+1] {
+		delete(c.sent, c.Line+1)
+		c.Line++
+	}
+}
+
+// save writes the checkpoint in a temporary file then renames it,
+// so the checkpoint is never left half written
+func (c *Checkpoint) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	c.Sent = make([]int, 0, len(c.sent))
+	for line := range c.sent {
+		c.Sent = append(c.Sent, line)
+	}
+	sort.Ints(c.Sent)
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
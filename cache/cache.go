@@ -0,0 +1,199 @@
+// Package cache adds a read-through cache in front of the reads of the tags and customers of a Skalin client.
+//
+// The entries expire after a TTL, and the customers are invalidated when the same client saves, updates or deletes a customer.
+// The entries are stored as JSON in a Backend: an in-memory LRU by default, or a shared store like Redis.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+)
+
+const DefaultCapacity = 1000
+
+// Backend stores the cached entries. It must be safe for concurrent use
+type Backend interface {
+	// Get returns false if the key is not found or has expired
+	Get(key string) ([]byte, bool, error)
+	// Set stores the value for ttl, a value without ttl does not expire
+	Set(key string, value []byte, ttl time.Duration) error
+	// DeletePrefix removes all the keys starting with prefix
+	DeletePrefix(prefix string) error
+}
+
+type Kind string
+
+const (
+	Tags      Kind = "tags"
+	Customers Kind = "customers"
+)
+
+type Options struct {
+	TTL     time.Duration
+	Backend Backend // if nil, an LRU of DefaultCapacity entries is used
+	Prefix  string  // prefix of the keys, to share a backend between several clients
+	// OnError is called with the errors of the backend. The cache never fails a call:
+	// a failed read of the backend is a miss, a failed write or invalidation is ignored
+	OnError func(error)
+}
+
+var DefaultOptions = Options{
+	TTL:    5 * time.Minute,
+	Prefix: "skalin:",
+}
+
+// Client is a Skalin client with the reads of the tags and customers cached.
+// The other methods are the methods of the wrapped client
+type Client struct {
+	skalinsdk.Skalin
+	backend Backend
+	ttl     time.Duration
+	prefix  string
+	onError func(error)
+	// generations are incremented before and after each write, so a read loaded during a write is not cached
+	mu          sync.RWMutex
+	generations map[Kind]uint64
+}
+
+var _ skalinsdk.Skalin = &Client{}
+
+func New(client skalinsdk.Skalin, opts Options) *Client {
+	c := &Client{
+		Skalin:  client,
+		backend: opts.Backend,
+		ttl:     opts.TTL,
+		prefix:  opts.Prefix,
+		onError: opts.OnError,
+
+		generations: make(map[Kind]uint64),
+	}
+	if c.backend == nil {
+		c.backend = NewLRU(DefaultCapacity)
+	}
+	if c.ttl == 0 {
+		c.ttl = DefaultOptions.TTL
+	}
+	return c
+}
+
+// Invalidate removes the cached entries of the kinds, or of all the kinds if none is given
+func (c *Client) Invalidate(kinds ...Kind) error {
+	if len(kinds) == 0 {
+		kinds = []Kind{Tags, Customers}
+	}
+	for _, kind := range kinds {
+		if err := c.backend.DeletePrefix(c.kindPrefix(kind)); err != nil {
+			return fmt.Errorf("error to invalidate %v: %w", kind, err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) GetTags(params *skalinsdk.GetParams) ([]skalinsdk.Tag, error) {
+	return readThrough(c, Tags, c.key(Tags, "list", params), func() ([]skalinsdk.Tag, error) {
+		return c.Skalin.GetTags(params)
+	})
+}
+
+func (c *Client) GetTagByID(id string) (*skalinsdk.Tag, error) {
+	return readThrough(c, Tags, c.key(Tags, "id", id), func() (*skalinsdk.Tag, error) {
+		return c.Skalin.GetTagByID(id)
+	})
+}
+
+func (c *Client) GetCustomers(params *skalinsdk.GetParams) ([]skalinsdk.Customer, error) {
+	return readThrough(c, Customers, c.key(Customers, "list", params), func() ([]skalinsdk.Customer, error) {
+		return c.Skalin.GetCustomers(params)
+	})
+}
+
+func (c *Client) SaveCustomer(customer skalinsdk.Customer) (*skalinsdk.Customer, error) {
+	c.invalidate(Customers)
+	defer c.invalidate(Customers)
+	return c.Skalin.SaveCustomer(customer)
+}
+
+func (c *Client) UpdateCustomer(customer skalinsdk.Customer) (*skalinsdk.Customer, error) {
+	c.invalidate(Customers)
+	defer c.invalidate(Customers)
+	return c.Skalin.UpdateCustomer(customer)
+}
+
+func (c *Client) DeleteCustomer(customer skalinsdk.Customer) error {
+	c.invalidate(Customers)
+	defer c.invalidate(Customers)
+	return c.Skalin.DeleteCustomer(customer)
+}
+
+// invalidate is called before and after the writes, even if the write failed because it may have been applied before the error
+func (c *Client) invalidate(kind Kind) {
+	c.mu.Lock()
+	c.generations[kind]++
+	c.mu.Unlock()
+	if err := c.Invalidate(kind); err != nil {
+		c.reportError(err)
+	}
+}
+
+func (c *Client) reportError(err error) {
+	if c.onError != nil {
+		c.onError(err)
+	}
+}
+
+func (c *Client) kindPrefix(kind Kind) string {
+	return c.prefix + string(kind) + ":"
+}
+
+// key returns the key of a read, the params are part of the key as JSON
+func (c *Client) key(kind Kind, read string, params interface{}) string {
+	b, err := json.Marshal(params)
+	if err != nil {
+		// params are ids or GetParams, which can always be marshalled
+		b = []byte(fmt.Sprintf("%v", params))
+	}
+	return c.kindPrefix(kind) + read + ":" + string(b)
+}
+
+// readThrough returns the cached value of key, or loads it and caches it. Errors are never cached,
+// and neither are the values loaded while a write of the kind was running
+func readThrough[T any](c *Client, kind Kind, key string, load func() (T, error)) (T, error) {
+	b, found, err := c.backend.Get(key)
+	if err != nil {
+		c.reportError(fmt.Errorf("error to get %v: %w", key, err))
+	}
+	if found {
+		var value T
+		err := json.Unmarshal(b, &value)
+		if err == nil {
+			return value, nil
+		}
+		c.reportError(fmt.Errorf("error to unmarshal %v: %w", key, err))
+	}
+	c.mu.RLock()
+	generation := c.generations[kind]
+	c.mu.RUnlock()
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	b, err = json.Marshal(value)
+	if err != nil {
+		c.reportError(fmt.Errorf("error to marshal %v: %w", key, err))
+		return value, nil
+	}
+	// the lock is held until the value is set, so a write starting now invalidates it
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.generations[kind] != generation {
+		return value, nil
+	}
+	if err := c.backend.Set(key, b, c.ttl); err != nil {
+		c.reportError(fmt.Errorf("error to set %v: %w", key, err))
+	}
+	return value, nil
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// failingBackend fails all the calls
+type failingBackend struct{}
+
+func (failingBackend) Get(string) ([]byte, bool, error) { return nil, false, errors.New("unreachable") }
+func (failingBackend) Set(string, []byte, time.Duration) error {
+	return errors.New("unreachable")
+}
+func (failingBackend) DeletePrefix(string) error { return errors.New("unreachable") }
+
+func TestClient(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		mockSkalin := skalinsdk.NewMockSkalin(t)
+		// expected once, the second call is read from the cache
+		mockSkalin.ExpectGetTagByID("1").Return(&skalinsdk.Tag{Id: "1", Name: "Tag"}, nil).Once()
+		mockSkalin.ExpectGetCustomers(mock.Anything).Return([]skalinsdk.Customer{{
+			Id:               "1",
+			Name:             "Karnott",
+			CustomAttributes: skalinsdk.CustomAttributes{"region": "north"},
+		}}, nil).Once()
+
+		client := New(mockSkalin, DefaultOptions)
+		for i := 0; i < 2; i++ {
+			tag, err := client.GetTagByID("1")
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "Tag", tag.Name)
+			customers, err := client.GetCustomers(nil)
+			if !assert.NoError(t, err) || !assert.Len(t, customers, 1) {
+				return
+			}
+			assert.Equal(t, "north", customers[0].CustomAttributes["region"])
+		}
+	})
+
+	t.Run("Invalidation", func(t *testing.T) {
+		mockSkalin := skalinsdk.NewMockSkalin(t)
+		mockSkalin.ExpectGetCustomers(mock.Anything).Return([]skalinsdk.Customer{{Id: "1"}}, nil).Times(3)
+		mockSkalin.ExpectGetTags(mock.Anything).Return([]skalinsdk.Tag{{Id: "1"}}, nil).Twice()
+		mockSkalin.ExpectSaveCustomer(mock.Anything).Return(nil, errors.New("error to save")).Once()
+
+		client := New(mockSkalin, DefaultOptions)
+		_, _ = client.GetCustomers(nil)
+		_, _ = client.GetTags(nil)
+		// invalidated even if the save failed
+		_, err := client.SaveCustomer(skalinsdk.Customer{RefId: "1"})
+		assert.Error(t, err)
+		_, _ = client.GetCustomers(nil)
+		_, _ = client.GetTags(nil)
+
+		assert.NoError(t, client.Invalidate())
+		_, _ = client.GetCustomers(nil)
+		_, _ = client.GetTags(nil)
+	})
+
+	t.Run("TTL and params", func(t *testing.T) {
+		mockSkalin := skalinsdk.NewMockSkalin(t)
+		size := 10
+		mockSkalin.ExpectGetTags((*skalinsdk.GetParams)(nil)).Return([]skalinsdk.Tag{{Id: "1"}}, nil).Twice()
+		mockSkalin.ExpectGetTags(&skalinsdk.GetParams{Size: &size}).Return([]skalinsdk.Tag{{Id: "2"}}, nil).Once()
+
+		backend := NewLRU(10)
+		now := time.Now()
+		backend.now = func() time.Time { return now }
+		client := New(mockSkalin, Options{TTL: time.Minute, Backend: backend})
+		tags, _ := client.GetTags(nil)
+		assert.Equal(t, "1", tags[0].Id)
+		tags, _ = client.GetTags(&skalinsdk.GetParams{Size: &size})
+		assert.Equal(t, "2", tags[0].Id)
+		_, _ = client.GetTags(nil)
+		now = now.Add(time.Minute)
+		_, _ = client.GetTags(nil)
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		mockSkalin := skalinsdk.NewMockSkalin(t)
+		mockSkalin.ExpectGetTagByID("1").Return(nil, errors.New("tag not found")).Once()
+		mockSkalin.ExpectGetTagByID("1").Return(&skalinsdk.Tag{Id: "1"}, nil).Twice()
+		mockSkalin.ExpectDeleteCustomer(mock.Anything).Return(nil).Once()
+
+		backendErrors := 0
+		client := New(mockSkalin, Options{Backend: failingBackend{}, OnError: func(error) { backendErrors++ }})
+		// errors are not cached
+		_, err := client.GetTagByID("1")
+		assert.Error(t, err)
+		// the calls don't fail when the backend is down
+		for i := 0; i < 2; i++ {
+			tag, err := client.GetTagByID("1")
+			assert.NoError(t, err)
+			assert.Equal(t, "1", tag.Id)
+		}
+		assert.NoError(t, client.DeleteCustomer(skalinsdk.Customer{Id: "1"}))
+		// the customers are invalidated before and after the delete
+		assert.Equal(t, 7, backendErrors)
+	})
+
+	t.Run("Concurrent read and write", func(t *testing.T) {
+		mockSkalin := skalinsdk.NewMockSkalin(t)
+		loading := make(chan struct{})
+		release := make(chan struct{})
+		// the first read loads the customers before the save, and returns after it
+		mockSkalin.ExpectGetCustomers(mock.Anything).Return([]skalinsdk.Customer{{Id: "1", Name: "Before"}}, nil).Once().Run(func(mock.Arguments) {
+			close(loading)
+			<-release
+		})
+		mockSkalin.ExpectSaveCustomer(mock.Anything).Return(&skalinsdk.Customer{Id: "1", Name: "After"}, nil).Once()
+		mockSkalin.ExpectGetCustomers(mock.Anything).Return([]skalinsdk.Customer{{Id: "1", Name: "After"}}, nil).Once()
+
+		client := New(mockSkalin, DefaultOptions)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			customers, err := client.GetCustomers(nil)
+			if assert.NoError(t, err) && assert.Len(t, customers, 1) {
+				assert.Equal(t, "Before", customers[0].Name)
+			}
+		}()
+		<-loading
+		_, err := client.SaveCustomer(skalinsdk.Customer{Id: "1", Name: "After"})
+		assert.NoError(t, err)
+		close(release)
+		<-done
+
+		// the customers loaded before the save are not cached
+		customers, err := client.GetCustomers(nil)
+		if assert.NoError(t, err) && assert.Len(t, customers, 1) {
+			assert.Equal(t, "After", customers[0].Name)
+		}
+	})
+}
+
+func TestLRU(t *testing.T) {
+	lru := NewLRU(2)
+	assert.NoError(t, lru.Set("a", []byte("1"), 0))
+	assert.NoError(t, lru.Set("b", []byte("2"), 0))
+	_, found, _ := lru.Get("a")
+	assert.True(t, found)
+	// b is the least recently used
+	assert.NoError(t, lru.Set("c", []byte("3"), 0))
+	_, found, _ = lru.Get("b")
+	assert.False(t, found)
+	assert.Equal(t, 2, lru.Len())
+
+	assert.NoError(t, lru.Set("prefix:a", []byte("4"), 0))
+	assert.NoError(t, lru.DeletePrefix("prefix:"))
+	_, found, _ = lru.Get("prefix:a")
+	assert.False(t, found)
+	value, found, _ := lru.Get("c")
+	assert.True(t, found)
+	assert.Equal(t, []byte("3"), value)
+}
@@ -0,0 +1,219 @@
+package skalinsdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling Skalin while the circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+type CircuitBreakerOptions struct {
+	ConsecutiveFailures int           // the circuit opens after this number of consecutive failures, 0 to disable
+	ErrorRate           float64       // the circuit opens when the rate of failures in the window reaches it (between 0 and 1), 0 to disable
+	MinRequests         int           // minimum number of requests in the window before the error rate is checked
+	Window              time.Duration // duration of the window of the error rate
+	CoolDown            time.Duration // duration of the open state, before a probe request is allowed
+}
+
+var DefaultCircuitBreakerOptions = CircuitBreakerOptions{
+	ConsecutiveFailures: 5,
+	ErrorRate:           0.5,
+	MinRequests:         20,
+	Window:              time.Minute,
+	CoolDown:            30 * time.Second,
+}
+
+// CircuitBreaker stops calling Skalin after too many failures.
+// A failure is a request which did not reach Skalin, or which was answered with a rate limit (429) or a server error (5xx).
+// A cancelled request is neither a failure nor a success.
+// Once open, the requests fail with ErrCircuitOpen until the cool-down is over,
+// then a single probe request is sent: the circuit is closed if it succeeds, else it is open again.
+// A cancelled probe leaves the circuit half-open, so the next request is the probe
+type CircuitBreaker struct {
+	opts CircuitBreakerOptions
+	now  func() time.Time
+
+	mu                  sync.Mutex
+	state               CircuitState
+	consecutiveFailures int
+	windowStart         time.Time
+	windowRequests      int
+	windowFailures      int
+	openedAt            time.Time
+	probing             bool
+}
+
+func NewCircuitBreaker(opts CircuitBreakerOptions) *CircuitBreaker {
+	return &CircuitBreaker{opts: opts, now: time.Now, state: CircuitClosed}
+}
+
+// State returns the state of the breaker, to be used in health checks
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && b.coolDownOver() {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Middleware fails fast with ErrCircuitOpen while the breaker is open.
+// The middlewares added after it (like RetryMiddleware) are not called in this case
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (*Response, error) {
+			probe, ok := b.allow()
+			if !ok {
+				return nil, ErrCircuitOpen
+			}
+			res, err := next(req)
+			b.record(probe, circuitOutcomeOf(res, err))
+			return res, err
+		}
+	}
+}
+
+func (b *CircuitBreaker) coolDownOver() bool {
+	return !b.now().Before(b.openedAt.Add(b.opts.CoolDown))
+}
+
+// allow returns false if the request must not be sent, and true for probe if the request is the probe of the half-open circuit
+func (b *CircuitBreaker) allow() (probe bool, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if !b.coolDownOver() {
+			return false, false
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return true, true
+	case CircuitHalfOpen:
+		// only one probe at a time
+		if b.probing {
+			return false, false
+		}
+		b.probing = true
+		return true, true
+	}
+	return false, true
+}
+
+type circuitOutcome int
+
+const (
+	circuitSuccess circuitOutcome = iota
+	circuitFailure
+	circuitCancelled
+)
+
+func (b *CircuitBreaker) record(probe bool, outcome circuitOutcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+		switch outcome {
+		case circuitFailure:
+			b.open()
+		case circuitSuccess:
+			b.close()
+		}
+		return
+	}
+	if b.state != CircuitClosed || outcome == circuitCancelled {
+		// request allowed before the circuit was opened by another one, only the probe changes the state
+		return
+	}
+	failure := outcome == circuitFailure
+
+	now := b.now()
+	if b.windowStart.IsZero() || now.Sub(b.windowStart) >= b.opts.Window {
+		b.windowStart = now
+		b.windowRequests = 0
+		b.windowFailures = 0
+	}
+	b.windowRequests++
+	if !failure {
+		b.consecutiveFailures = 0
+		return
+	}
+	b.windowFailures++
+	b.consecutiveFailures++
+	if b.opts.ConsecutiveFailures > 0 && b.consecutiveFailures >= b.opts.ConsecutiveFailures {
+		b.open()
+		return
+	}
+	if b.opts.ErrorRate > 0 && b.windowRequests >= b.opts.MinRequests &&
+		float64(b.windowFailures)/float64(b.windowRequests) >= b.opts.ErrorRate {
+		b.open()
+	}
+}
+
+func (b *CircuitBreaker) open() {
+	b.state = CircuitOpen
+	b.openedAt = b.now()
+}
+
+func (b *CircuitBreaker) close() {
+	b.state = CircuitClosed
+	b.consecutiveFailures = 0
+	b.windowStart = time.Time{}
+	b.windowRequests = 0
+	b.windowFailures = 0
+}
+
+func circuitOutcomeOf(res *Response, err error) circuitOutcome {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return circuitCancelled
+	case err != nil || res == nil:
+		return circuitFailure
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError:
+		return circuitFailure
+	}
+	return circuitSuccess
+}
+
+// CircuitBreakers are the breakers of the API and of the collect endpoint,
+// so an outage of one of them does not stop the calls to the other one
+type CircuitBreakers struct {
+	API     *CircuitBreaker
+	Collect *CircuitBreaker
+}
+
+func NewCircuitBreakers(opts CircuitBreakerOptions) *CircuitBreakers {
+	return &CircuitBreakers{
+		API:     NewCircuitBreaker(opts),
+		Collect: NewCircuitBreaker(opts),
+	}
+}
+
+// Middleware uses the breaker of the endpoint of the request
+func (b *CircuitBreakers) Middleware() Middleware {
+	apiMiddleware := b.API.Middleware()
+	collectMiddleware := b.Collect.Middleware()
+	return func(next Handler) Handler {
+		api := apiMiddleware(next)
+		collect := collectMiddleware(next)
+		return func(req *Request) (*Response, error) {
+			if strings.HasPrefix(req.URL, SKALIN_HIT_URL) {
+				return collect(req)
+			}
+			return api(req)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package skalinsdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestBreaker returns a breaker with a clock moved by the returned function
+func newTestBreaker(opts CircuitBreakerOptions) (*CircuitBreaker, func(time.Duration)) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(opts)
+	breaker.now = func() time.Time { return now }
+	return breaker, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	statusCode := http.StatusServiceUnavailable
+	calls := 0
+	handler := func(req *Request) (*Response, error) {
+		calls++
+		return &Response{StatusCode: statusCode}, nil
+	}
+	call := func(h Handler) error {
+		_, err := h(&Request{Context: context.Background()})
+		return err
+	}
+
+	t.Run("Consecutive failures", func(t *testing.T) {
+		statusCode, calls = http.StatusServiceUnavailable, 0
+		breaker, advance := newTestBreaker(CircuitBreakerOptions{ConsecutiveFailures: 2, CoolDown: time.Minute})
+		h := breaker.Middleware()(handler)
+		assert.NoError(t, call(h))
+		assert.Equal(t, CircuitClosed, breaker.State())
+		assert.NoError(t, call(h))
+		assert.Equal(t, CircuitOpen, breaker.State())
+
+		assert.ErrorIs(t, call(h), ErrCircuitOpen)
+		assert.Equal(t, 2, calls)
+
+		// the probe fails, the circuit is open again
+		advance(time.Minute)
+		assert.Equal(t, CircuitHalfOpen, breaker.State())
+		assert.NoError(t, call(h))
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, CircuitOpen, breaker.State())
+		assert.ErrorIs(t, call(h), ErrCircuitOpen)
+
+		// the probe succeeds, the circuit is closed
+		advance(time.Minute)
+		statusCode = http.StatusOK
+		assert.NoError(t, call(h))
+		assert.Equal(t, CircuitClosed, breaker.State())
+		assert.NoError(t, call(h))
+		assert.Equal(t, 5, calls)
+	})
+
+	t.Run("Error rate", func(t *testing.T) {
+		calls = 0
+		breaker, advance := newTestBreaker(CircuitBreakerOptions{ErrorRate: 0.5, MinRequests: 4, Window: time.Minute, CoolDown: time.Minute})
+		h := breaker.Middleware()(handler)
+		for _, code := range []int{http.StatusOK, http.StatusInternalServerError, http.StatusOK} {
+			statusCode = code
+			assert.NoError(t, call(h))
+		}
+		// a new window starts
+		advance(time.Minute)
+		for _, code := range []int{http.StatusInternalServerError, http.StatusOK, http.StatusTooManyRequests} {
+			statusCode = code
+			assert.NoError(t, call(h))
+			assert.Equal(t, CircuitClosed, breaker.State())
+		}
+		statusCode = http.StatusOK
+		assert.NoError(t, call(h))
+		assert.Equal(t, CircuitClosed, breaker.State())
+		statusCode = http.StatusBadGateway
+		assert.NoError(t, call(h))
+		assert.Equal(t, CircuitOpen, breaker.State())
+	})
+
+	t.Run("Cancelled probe", func(t *testing.T) {
+		breaker, advance := newTestBreaker(CircuitBreakerOptions{ConsecutiveFailures: 1, CoolDown: time.Minute})
+		statusCode = http.StatusServiceUnavailable
+		h := breaker.Middleware()(handler)
+		assert.NoError(t, call(h))
+		assert.Equal(t, CircuitOpen, breaker.State())
+
+		advance(time.Minute)
+		cancelled := breaker.Middleware()(func(req *Request) (*Response, error) {
+			return nil, context.Canceled
+		})
+		assert.ErrorIs(t, call(cancelled), context.Canceled)
+		assert.Equal(t, CircuitHalfOpen, breaker.State())
+		// the next request is the probe
+		statusCode = http.StatusOK
+		assert.NoError(t, call(h))
+		assert.Equal(t, CircuitClosed, breaker.State())
+	})
+
+	t.Run("Request admitted before the probe", func(t *testing.T) {
+		breaker, advance := newTestBreaker(CircuitBreakerOptions{ConsecutiveFailures: 1, CoolDown: time.Minute})
+		// admitted while the circuit is closed, it finishes once the circuit is half-open
+		slow, ok := breaker.allow()
+		assert.True(t, ok)
+		assert.False(t, slow)
+		statusCode = http.StatusServiceUnavailable
+		assert.NoError(t, call(breaker.Middleware()(handler)))
+		advance(time.Minute)
+		probe, ok := breaker.allow()
+		assert.True(t, ok)
+		assert.True(t, probe)
+
+		breaker.record(slow, circuitSuccess)
+		assert.Equal(t, CircuitHalfOpen, breaker.State())
+		breaker.record(probe, circuitFailure)
+		assert.Equal(t, CircuitOpen, breaker.State())
+	})
+
+	t.Run("Client errors", func(t *testing.T) {
+		statusCode, calls = http.StatusNotFound, 0
+		breaker, _ := newTestBreaker(CircuitBreakerOptions{ConsecutiveFailures: 1})
+		h := breaker.Middleware()(handler)
+		assert.NoError(t, call(h))
+		assert.NoError(t, call(h))
+		assert.Equal(t, CircuitClosed, breaker.State())
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestCircuitBreakers(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	breakers := NewCircuitBreakers(CircuitBreakerOptions{ConsecutiveFailures: 2, CoolDown: time.Minute})
+	skalinApi, err := New("clientId", "clientApiId", "clientApiSecret", WithHTTPClient(server.Client()), WithCircuitBreakers(breakers))
+	if !assert.NoError(t, err) {
+		return
+	}
+	tracker, err := NewTracker("clientId", WithHTTPClient(server.Client()), WithCircuitBreakers(breakers))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	server.InjectFault("/hit", skalintest.Fault{Status: http.StatusInternalServerError})
+	results, err := tracker.HitBatchWithOptions([]HitTrack{newTestHit("1111111111111111")}, HitBatchOptions{Concurrency: 1, MaxRetries: 3})
+	assert.Error(t, err)
+	if assert.Len(t, results, 1) {
+		// the last attempt is stopped by the breaker
+		assert.Equal(t, 3, results[0].Attempts)
+		assert.True(t, errors.Is(results[0].Err, ErrCircuitOpen))
+	}
+	assert.Equal(t, CircuitOpen, breakers.Collect.State())
+
+	// the API is still called
+	_, err = skalinApi.GetTags(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, CircuitClosed, breakers.API.State())
+}
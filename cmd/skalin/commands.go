@@ -0,0 +1,361 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/agreements"
+	"github.com/karnott/skalin-sdk/internal/records"
+)
+
+// commonFlags are the flags of all the actions
+type commonFlags struct {
+	config  string
+	output  string
+	columns string
+	dryRun  bool
+}
+
+func newFlagSet(name string, stderr io.Writer, common *commonFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&common.config, "config", "", "JSON config file with clientId, clientApiId and clientApiSecret")
+	fs.StringVar(&common.output, "output", outputTable, "output format: table, json or csv")
+	fs.StringVar(&common.output, "o", outputTable, "shorthand for --output")
+	fs.StringVar(&common.columns, "columns", "", "comma separated columns of the table and CSV outputs")
+	fs.BoolVar(&common.dryRun, "dry-run", false, "print the write without sending it to Skalin")
+	return fs
+}
+
+// parseArgs parses the flags placed before, between or after the positional args
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0)
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// filterFlag maps the repeated `--filter key=value` flags to the filters of GetParams
+type filterFlag map[string]interface{}
+
+func (f filterFlag) String() string {
+	return fmt.Sprintf("%v", map[string]interface{}(f))
+}
+
+func (f filterFlag) Set(value string) error {
+	key, v, found := strings.Cut(value, "=")
+	if !found {
+		key, v, found = strings.Cut(value, ":")
+	}
+	if !found || key == "" {
+		return fmt.Errorf("filter %q must be key=value", value)
+	}
+	f[key] = v
+	return nil
+}
+
+func (a *app) client(common commonFlags) (skalinsdk.Skalin, error) {
+	cfg, err := a.loadConfig(common.config, true)
+	if err != nil {
+		return nil, err
+	}
+	opts := append([]skalinsdk.Option{skalinsdk.WithCustomAttributes(cfg.CustomAttributes...)}, a.opts...)
+	return skalinsdk.New(cfg.ClientID, cfg.ClientApiID, cfg.ClientApiSecret, opts...)
+}
+
+func (a *app) runResource(r resource, action string, args []string) error {
+	var common commonFlags
+	fs := newFlagSet(action, a.stderr, &common)
+	var data, file string
+	var page, size int
+	var sort string
+	filters := filterFlag{}
+	switch action {
+	case "list":
+		fs.Var(filters, "filter", "filter `key=value`, can be repeated")
+		fs.IntVar(&page, "page", 0, "only get this page (all the pages by default)")
+		fs.IntVar(&size, "size", 0, "size of the pages")
+		fs.StringVar(&sort, "sort", "", "field to sort by, prefixed by - for descending order")
+	case "save", "update":
+		if r.save == nil {
+			return fmt.Errorf("%v is not supported", action)
+		}
+		fs.StringVar(&data, "data", "", "JSON of the entity")
+		fs.StringVar(&file, "file", "", "JSON file of the entity, - for stdin")
+	case "get", "delete":
+		if action == "delete" && r.delete == nil {
+			return fmt.Errorf("%v is not supported", action)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	columns := r.columns
+	if common.columns != "" {
+		columns = strings.Split(common.columns, ",")
+	}
+
+	var id string
+	switch action {
+	case "get", "update", "delete":
+		if len(positional) != 1 {
+			return fmt.Errorf("%v expects the id of the entity", action)
+		}
+		id = positional[0]
+	default:
+		if len(positional) != 0 {
+			return fmt.Errorf("unexpected args: %v", strings.Join(positional, " "))
+		}
+	}
+
+	var entity interface{}
+	switch action {
+	case "save", "update":
+		b, err := a.readData(data, file)
+		if err != nil {
+			return err
+		}
+		entity, err = r.decode(b, id)
+		if err != nil {
+			return err
+		}
+	case "delete":
+		entity, err = r.decode([]byte("{}"), id)
+		if err != nil {
+			return err
+		}
+	}
+	if entity != nil && common.dryRun {
+		return a.printDryRun(action, entity)
+	}
+
+	client, err := a.client(common)
+	if err != nil {
+		return err
+	}
+	switch action {
+	case "list":
+		params := &skalinsdk.GetParams{}
+		if page > 0 {
+			params.Page = &page
+		}
+		if size > 0 {
+			params.Size = &size
+		}
+		if sort != "" {
+			params.Sort = &sort
+		}
+		if len(filters) > 0 {
+			params.Filters = filters
+		}
+		items, err := r.list(client, params)
+		if err != nil {
+			return err
+		}
+		return writeItems(a.stdout, common.output, columns, items)
+	case "get":
+		item, err := r.get(client, id)
+		if err != nil {
+			return err
+		}
+		return writeItems(a.stdout, common.output, columns, []interface{}{item})
+	case "save", "update":
+		write := r.save
+		if action == "update" {
+			write = r.update
+		}
+		item, err := write(client, entity)
+		if err != nil {
+			return err
+		}
+		return writeItems(a.stdout, common.output, columns, []interface{}{item})
+	case "delete":
+		if err := r.delete(client, entity); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "%v deleted\n", id)
+	}
+	return nil
+}
+
+// readData returns the JSON given with --data or --file
+func (a *app) readData(data, file string) ([]byte, error) {
+	switch {
+	case data != "" && file != "":
+		return nil, errors.New("--data and --file can't be used together")
+	case data != "":
+		return []byte(data), nil
+	case file == "-":
+		return io.ReadAll(a.stdin)
+	case file != "":
+		return os.ReadFile(file)
+	}
+	return nil, errors.New("--data or --file is required")
+}
+
+func (a *app) printDryRun(action string, value interface{}) error {
+	encoder := json.NewEncoder(a.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]interface{}{
+		"dryRun": true,
+		"action": action,
+		"data":   value,
+	})
+}
+
+func (a *app) sendHit(args []string) error {
+	var common commonFlags
+	fs := newFlagSet("send", a.stderr, &common)
+	var action, visitorID, visitID, identityID, identityEmail, event, eventName, customerID, ts, timeZone, url string
+	fs.StringVar(&action, "action", string(skalinsdk.HitActionEvent), "action of the hit: ev (event) or ui (user identity)")
+	fs.StringVar(&visitorID, "visitor-id", "", "visitor id (16 characters)")
+	fs.StringVar(&visitID, "visit-id", "", "visit id (16 characters)")
+	fs.StringVar(&identityID, "identity-id", "", "id of the contact")
+	fs.StringVar(&identityEmail, "identity-email", "", "email of the contact")
+	fs.StringVar(&event, "event", "", "name of the event")
+	fs.StringVar(&eventName, "event-name", "", "event_name of the event (same as --event by default)")
+	fs.StringVar(&customerID, "customer-id", "", "id of the customer")
+	fs.StringVar(&ts, "ts", "", "time of the hit (RFC 3339), now by default")
+	fs.StringVar(&timeZone, "timezone", "", "IANA timezone of the contact")
+	fs.StringVar(&url, "url", "", "url of the hit")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected args: %v", strings.Join(positional, " "))
+	}
+
+	hit := skalinsdk.HitTrack{
+		Action:    skalinsdk.HitAction(action),
+		VisitorID: visitorID,
+		VisitID:   visitID,
+		TimeZone:  timeZone,
+	}
+	if identityID != "" {
+		hit.Identity.ID = &identityID
+	}
+	if identityEmail != "" {
+		hit.Identity.Email = &identityEmail
+	}
+	if event != "" {
+		if eventName == "" {
+			eventName = event
+		}
+		hit.Event = &skalinsdk.HitEvent{Name: event, EventName: eventName}
+	}
+	if customerID != "" {
+		hit.CustomerID = &customerID
+	}
+	if url != "" {
+		hit.URL = &url
+	}
+	if ts != "" {
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return fmt.Errorf("error to parse --ts: %w", err)
+		}
+		hit.Ts = &t
+	}
+	if common.dryRun {
+		return a.printDryRun("send", hit)
+	}
+
+	cfg, err := a.loadConfig(common.config, false)
+	if err != nil {
+		return err
+	}
+	tracker, err := skalinsdk.NewTracker(cfg.ClientID, a.opts...)
+	if err != nil {
+		return err
+	}
+	if _, _, err := tracker.Hit(hit); err != nil {
+		return err
+	}
+	fmt.Fprintln(a.stdout, "hit sent")
+	return nil
+}
+
+// renewAgreements rolls forward the renewal dates of the due agreements, see agreements.Renew
+func (a *app) renewAgreements(args []string) error {
+	var common commonFlags
+	fs := newFlagSet("renew", a.stderr, &common)
+	var at, reportPath string
+	fs.StringVar(&at, "date", "", "date of the run (YYYY-MM-DD), today by default")
+	fs.StringVar(&reportPath, "report", "", "CSV or JSON Lines file of the renewals")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected args: %v", strings.Join(positional, " "))
+	}
+	opts := agreements.RenewOptions{DryRun: common.dryRun}
+	if at != "" {
+		opts.At, err = time.Parse(time.DateOnly, at)
+		if err != nil {
+			return fmt.Errorf("error to parse --date: %w", err)
+		}
+	}
+	var reportFormat agreements.Format
+	if reportPath != "" {
+		if reportFormat, err = records.FormatFromPath(reportPath); err != nil {
+			return err
+		}
+	}
+
+	client, err := a.client(common)
+	if err != nil {
+		return err
+	}
+	report, err := agreements.Renew(client, opts)
+	if err != nil {
+		return err
+	}
+	if reportPath != "" {
+		if err := writeRenewReport(reportPath, reportFormat, report); err != nil {
+			return err
+		}
+	}
+	columns := agreements.RenewalColumns
+	if common.columns != "" {
+		columns = strings.Split(common.columns, ",")
+	}
+	rows := report.Rows()
+	if err := writeItems(a.stdout, common.output, columns, toInterfaces(rows)); err != nil {
+		return err
+	}
+	fmt.Fprintln(a.stderr, report)
+	return nil
+}
+
+func writeRenewReport(path string, format agreements.Format, report *agreements.RenewReport) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}()
+	return report.WriteReport(file, format)
+}
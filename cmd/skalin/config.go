@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// config holds the credentials of the Skalin API and the custom attributes of the account
+type config struct {
+	ClientID         string   `json:"clientId"`
+	ClientApiID      string   `json:"clientApiId"`
+	ClientApiSecret  string   `json:"clientApiSecret"`
+	CustomAttributes []string `json:"customAttributes"`
+}
+
+// defaultConfigPath returns skalin/config.json in the user config dir (like ~/.config/skalin/config.json)
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "skalin", "config.json")
+}
+
+// loadConfig reads the config file if it exists, then the env vars which override it.
+// The config file given with --config must exist
+func (a *app) loadConfig(path string, needAPI bool) (config, error) {
+	var cfg config
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigPath()
+	}
+	if path != "" {
+		b, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(b, &cfg); err != nil {
+				return cfg, fmt.Errorf("error to unmarshal config %v: %w", path, err)
+			}
+		case explicit || !errors.Is(err, os.ErrNotExist):
+			return cfg, fmt.Errorf("error to read config %v: %w", path, err)
+		}
+	}
+	if v := a.getenv("SKALIN_CLIENT_ID"); v != "" {
+		cfg.ClientID = v
+	}
+	if v := a.getenv("SKALIN_CLIENT_API_ID"); v != "" {
+		cfg.ClientApiID = v
+	}
+	if v := a.getenv("SKALIN_CLIENT_API_SECRET"); v != "" {
+		cfg.ClientApiSecret = v
+	}
+	if cfg.ClientID == "" {
+		return cfg, errors.New("client id is not set (SKALIN_CLIENT_ID or clientId in the config file)")
+	}
+	if needAPI && (cfg.ClientApiID == "" || cfg.ClientApiSecret == "") {
+		return cfg, errors.New("API credentials are not set (SKALIN_CLIENT_API_ID and SKALIN_CLIENT_API_SECRET, or clientApiId and clientApiSecret in the config file)")
+	}
+	return cfg, nil
+}
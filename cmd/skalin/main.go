@@ -0,0 +1,79 @@
+// Command skalin reads and writes the Skalin entities from the command line.
+//
+//	skalin contacts list --filter customerId=123 -o csv
+//	skalin contacts get 5f0c...
+//	skalin contacts save --data '{"refId":"1","email":"contact@karnott.fr"}' --dry-run
+//	skalin agreements update 5f0c... --file agreement.json
+//	skalin agreements renew --dry-run --report renewals.csv
+//	skalin tags list
+//	skalin hit send --visitor-id 1111111111111111 --visit-id 2222222222222222 --identity-id 1 --event login
+//
+// The credentials are read from the SKALIN_CLIENT_ID, SKALIN_CLIENT_API_ID and SKALIN_CLIENT_API_SECRET env vars,
+// or from the JSON config file given with --config (default is skalin/config.json in the user config dir)
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+)
+
+const usage = `usage: skalin <command> <action> [flags] [args]
+
+commands:
+  contacts   list | get <id or refId> | save | update <id> | delete <id>
+  customers  list | get <id or refId> | save | update <id> | delete <id>
+  agreements list | get <id or refId> | save | update <id> | delete <id> | renew
+  tags       list | get <id>
+  hit        send
+
+Run "skalin <command> <action> --help" for the flags of an action.
+`
+
+var errUsage = errors.New("invalid usage")
+
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+	opts   []skalinsdk.Option // options of the client and tracker
+}
+
+func main() {
+	a := &app{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	if err := a.run(os.Args[1:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) && !errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func (a *app) run(args []string) error {
+	if len(args) < 2 {
+		fmt.Fprint(a.stderr, usage)
+		return errUsage
+	}
+	command, action := args[0], args[1]
+	if command == "hit" {
+		if action != "send" {
+			fmt.Fprint(a.stderr, usage)
+			return errUsage
+		}
+		return a.sendHit(args[2:])
+	}
+	if command == "agreements" && action == "renew" {
+		return a.renewAgreements(args[2:])
+	}
+	r, ok := resources[command]
+	if !ok {
+		fmt.Fprint(a.stderr, usage)
+		return errUsage
+	}
+	return a.runResource(r, action, args[2:])
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestApp(server *skalintest.Server, env map[string]string) (*app, *bytes.Buffer) {
+	stdout := &bytes.Buffer{}
+	return &app{
+		stdin:  strings.NewReader(""),
+		stdout: stdout,
+		stderr: &bytes.Buffer{},
+		getenv: func(key string) string { return env[key] },
+		opts:   []skalinsdk.Option{skalinsdk.WithHTTPClient(server.Client()), skalinsdk.WithLogger(skalinsdk.NewNopLogger())},
+	}, stdout
+}
+
+var testEnv = map[string]string{
+	"SKALIN_CLIENT_ID":         "clientId",
+	"SKALIN_CLIENT_API_ID":     "clientApiId",
+	"SKALIN_CLIENT_API_SECRET": "clientApiSecret",
+}
+
+func TestContacts(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	// an empty config dir, so no user config is read
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	t.Run("OK", func(t *testing.T) {
+		a, stdout := newTestApp(server, testEnv)
+		err := a.run([]string{"contacts", "save", "--data", `{"refId":"1","email":"contact@karnott.fr","tags":["a","b"]}`, "-o", "json"})
+		if !assert.NoError(t, err) {
+			return
+		}
+		var saved []skalinsdk.Contact
+		if !assert.NoError(t, json.Unmarshal(stdout.Bytes(), &saved)) || !assert.Len(t, saved, 1) {
+			return
+		}
+		id := saved[0].Id
+
+		a, stdout = newTestApp(server, testEnv)
+		assert.NoError(t, a.run([]string{"contacts", "update", id, "--data", `{"email":"contact2@karnott.fr"}`}))
+
+		a, stdout = newTestApp(server, testEnv)
+		assert.NoError(t, a.run([]string{"contacts", "list", "--filter", "refId=1", "-o", "csv", "--columns", "refId,email,tags"}))
+		assert.Equal(t, "refId,email,tags\n1,contact2@karnott.fr,\"a,b\"\n", stdout.String())
+
+		a, stdout = newTestApp(server, testEnv)
+		assert.NoError(t, a.run([]string{"contacts", "get", "1"}))
+		assert.Contains(t, stdout.String(), "EMAIL")
+		assert.Contains(t, stdout.String(), "contact2@karnott.fr")
+
+		a, stdout = newTestApp(server, testEnv)
+		assert.NoError(t, a.run([]string{"contacts", "get", id}))
+		assert.Contains(t, stdout.String(), "contact2@karnott.fr")
+		a, _ = newTestApp(server, testEnv)
+		assert.Error(t, a.run([]string{"contacts", "get", "unknown"}))
+
+		a, stdout = newTestApp(server, testEnv)
+		assert.NoError(t, a.run([]string{"contacts", "delete", id}))
+		assert.Equal(t, id+" deleted\n", stdout.String())
+		assert.Len(t, server.Entities(skalintest.Contacts), 0)
+	})
+
+	t.Run("Page", func(t *testing.T) {
+		for _, refID := range []string{"1", "2"} {
+			_, err := server.Seed(skalintest.Contacts, skalintest.Entity{"id": "page" + refID, "refId": refID})
+			assert.NoError(t, err)
+		}
+		defer server.Reset()
+		// only the page asked is fetched
+		a, stdout := newTestApp(server, testEnv)
+		assert.NoError(t, a.run([]string{"contacts", "list", "--page", "1", "--size", "1", "--sort", "refId", "-o", "csv", "--columns", "refId"}))
+		assert.Equal(t, "refId\n1\n", stdout.String())
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		// no credentials are needed
+		a, stdout := newTestApp(server, nil)
+		err := a.run([]string{"customers", "update", "123", "--data", `{"name":"Karnott"}`, "--dry-run"})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Contains(t, stdout.String(), `"dryRun": true`)
+		assert.Contains(t, stdout.String(), `"id": "123"`)
+		assert.Len(t, server.Entities(skalintest.Customers), 0)
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		a, _ := newTestApp(server, nil)
+		assert.Error(t, a.run([]string{"contacts", "list"}))
+
+		a, _ = newTestApp(server, testEnv)
+		assert.Error(t, a.run([]string{"contacts", "get"}))
+		assert.Error(t, a.run([]string{"contacts", "save"}))
+		assert.Error(t, a.run([]string{"tags", "save", "--data", "{}"}))
+		assert.Error(t, a.run([]string{"unknown", "list"}))
+		assert.Error(t, a.run([]string{"contacts", "list", "-o", "xml"}))
+	})
+}
+
+func TestConfigFile(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	_, err := server.Seed(skalintest.Tags, skalintest.Entity{"name": "Tag"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	err = os.WriteFile(path, []byte(`{"clientId":"clientId","clientApiId":"clientApiId","clientApiSecret":"clientApiSecret"}`), 0o600)
+	if !assert.NoError(t, err) {
+		return
+	}
+	a, stdout := newTestApp(server, nil)
+	assert.NoError(t, a.run([]string{"tags", "list", "--config", path, "-o", "csv", "--columns", "name"}))
+	assert.Equal(t, "name\nTag\n", stdout.String())
+
+	a, _ = newTestApp(server, nil)
+	assert.Error(t, a.run([]string{"tags", "list", "--config", filepath.Join(t.TempDir(), "unknown.json")}))
+}
+
+func TestSendHit(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	a, stdout := newTestApp(server, map[string]string{"SKALIN_CLIENT_ID": "clientId"})
+	err := a.run([]string{"hit", "send",
+		"--visitor-id", "1111111111111111",
+		"--visit-id", "2222222222222222",
+		"--identity-id", "1",
+		"--event", "login",
+		"--ts", "2023-01-02T03:04:05Z",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "hit sent\n", stdout.String())
+	assert.Len(t, server.Hits(), 1)
+
+	a, _ = newTestApp(server, map[string]string{"SKALIN_CLIENT_ID": "clientId"})
+	assert.Error(t, a.run([]string{"hit", "send", "--visitor-id", "1"}))
+}
+
+func TestRenewAgreements(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	_, err := server.Seed(skalintest.Agreements, skalintest.Entity{
+		"id": "a1", "refId": "1", "autoRenew": true, "engagement": 1, "engagementPeriod": "YEAR",
+		"startDate": "2023-01-01", "renewalDate": "2024-01-01",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	a, stdout := newTestApp(server, testEnv)
+	assert.NoError(t, a.run([]string{"agreements", "renew", "--date", "2024-06-15", "--dry-run", "-o", "csv", "--columns", "id,newRenewalDate,outcome"}))
+	assert.Equal(t, "id,newRenewalDate,outcome\na1,2025-01-01,dry-run\n", stdout.String())
+	assert.Equal(t, "2024-01-01", server.Entity(skalintest.Agreements, "a1")["renewalDate"])
+
+	report := filepath.Join(t.TempDir(), "renewals.jsonl")
+	a, _ = newTestApp(server, testEnv)
+	assert.NoError(t, a.run([]string{"agreements", "renew", "--date", "2024-06-15", "--report", report}))
+	assert.Equal(t, "2025-01-01", server.Entity(skalintest.Agreements, "a1")["renewalDate"])
+	b, err := os.ReadFile(report)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"outcome":"renewed"`)
+
+	a, _ = newTestApp(server, testEnv)
+	assert.Error(t, a.run([]string{"agreements", "renew", "--date", "15/06/2024"}))
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+// writeItems writes the entities in the output format.
+// The table and CSV formats only have the given columns, the JSON format has all the fields
+func writeItems(w io.Writer, format string, columns []string, items []interface{}) error {
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	case outputTable, outputCSV:
+		rows := make([][]string, 0, len(items))
+		for _, item := range items {
+			row, err := itemRow(item, columns)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, row)
+		}
+		if format == outputCSV {
+			writer := csv.NewWriter(w)
+			_ = writer.Write(columns)
+			_ = writer.WriteAll(rows)
+			return writer.Error()
+		}
+		writer := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, row := range rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		return writer.Flush()
+	}
+	return fmt.Errorf("unknown output format %q (table, json or csv)", format)
+}
+
+// itemRow returns the values of the columns, read from the JSON fields of the item
+func itemRow(item interface{}, columns []string) ([]string, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		row[i] = formatValue(fields[column])
+	}
+	return row, nil
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = formatValue(item)
+		}
+		return strings.Join(values, ",")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]string, len(keys))
+		for i, key := range keys {
+			values[i] = key + "=" + formatValue(v[key])
+		}
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+)
+
+// resource is the implementation of the actions of a command on the Skalin client
+type resource struct {
+	columns []string // default columns of the table and CSV outputs
+	list    func(s skalinsdk.Skalin, params *skalinsdk.GetParams) ([]interface{}, error)
+	get     func(s skalinsdk.Skalin, id string) (interface{}, error)
+	decode  func(data []byte, id string) (interface{}, error) // entity of a write, with the id of the command if any
+	save    func(s skalinsdk.Skalin, entity interface{}) (interface{}, error)
+	update  func(s skalinsdk.Skalin, entity interface{}) (interface{}, error)
+	delete  func(s skalinsdk.Skalin, entity interface{}) error
+}
+
+var resources = map[string]resource{
+	"contacts": entityResource(
+		[]string{"id", "refId", "customerId", "email", "firstName", "lastName", "phone", "tags", "lastActivityTs"},
+		skalinsdk.Skalin.GetContacts,
+		skalinsdk.Skalin.WalkContacts,
+		func(c skalinsdk.Contact) (string, string) { return c.Id, c.RefId },
+		func(c *skalinsdk.Contact, id string) { c.Id = id },
+		skalinsdk.Skalin.SaveContact,
+		skalinsdk.Skalin.UpdateContact,
+		skalinsdk.Skalin.DeleteContact,
+	),
+	"customers": entityResource(
+		[]string{"id", "refId", "name", "stage", "tags", "lastActivityTs"},
+		skalinsdk.Skalin.GetCustomers,
+		skalinsdk.Skalin.WalkCustomers,
+		func(c skalinsdk.Customer) (string, string) { return c.Id, c.RefId },
+		func(c *skalinsdk.Customer, id string) { c.Id = id },
+		skalinsdk.Skalin.SaveCustomer,
+		skalinsdk.Skalin.UpdateCustomer,
+		skalinsdk.Skalin.DeleteCustomer,
+	),
+	"agreements": entityResource(
+		[]string{"id", "refId", "customerId", "type", "plan", "startDate", "endDate", "renewalDate", "mrr", "fee"},
+		skalinsdk.Skalin.GetAgreements,
+		skalinsdk.Skalin.WalkAgreements,
+		func(a skalinsdk.Agreement) (string, string) { return a.Id, a.RefId },
+		func(a *skalinsdk.Agreement, id string) { a.Id = id },
+		skalinsdk.Skalin.SaveAgreement,
+		skalinsdk.Skalin.UpdateAgreement,
+		skalinsdk.Skalin.DeleteAgreement,
+	),
+	"tags": {
+		columns: []string{"id", "name", "type", "entity", "color"},
+		list: func(s skalinsdk.Skalin, params *skalinsdk.GetParams) ([]interface{}, error) {
+			return listEntities(s, params, skalinsdk.Skalin.GetTags, skalinsdk.Skalin.WalkTags)
+		},
+		get: func(s skalinsdk.Skalin, id string) (interface{}, error) {
+			return s.GetTagByID(id)
+		},
+	},
+}
+
+// entityResource builds the resource of an entity which can be written.
+// The API has no endpoint to get an entity, so get filters the list by id, then by refId
+func entityResource[T any](
+	columns []string,
+	list func(skalinsdk.Skalin, *skalinsdk.GetParams) ([]T, error),
+	walk func(skalinsdk.Skalin, *skalinsdk.GetParams, func([]T) error) error,
+	ids func(T) (string, string),
+	setID func(*T, string),
+	save func(skalinsdk.Skalin, T) (*T, error),
+	update func(skalinsdk.Skalin, T) (*T, error),
+	remove func(skalinsdk.Skalin, T) error,
+) resource {
+	return resource{
+		columns: columns,
+		list: func(s skalinsdk.Skalin, params *skalinsdk.GetParams) ([]interface{}, error) {
+			return listEntities(s, params, list, walk)
+		},
+		get: func(s skalinsdk.Skalin, id string) (interface{}, error) {
+			for _, field := range []string{"id", "refId"} {
+				entities, err := list(s, &skalinsdk.GetParams{Filters: map[string]interface{}{field: id}})
+				if err != nil {
+					return nil, err
+				}
+				for _, entity := range entities {
+					if entityID, refID := ids(entity); entityID == id || refID == id {
+						return entity, nil
+					}
+				}
+			}
+			return nil, fmt.Errorf("%v not found", id)
+		},
+		decode: func(data []byte, id string) (interface{}, error) {
+			var entity T
+			if err := json.Unmarshal(data, &entity); err != nil {
+				return nil, fmt.Errorf("error to unmarshal data: %w", err)
+			}
+			if id != "" {
+				setID(&entity, id)
+			}
+			return entity, nil
+		},
+		save: func(s skalinsdk.Skalin, entity interface{}) (interface{}, error) {
+			return save(s, entity.(T))
+		},
+		update: func(s skalinsdk.Skalin, entity interface{}) (interface{}, error) {
+			return update(s, entity.(T))
+		},
+		delete: func(s skalinsdk.Skalin, entity interface{}) error {
+			return remove(s, entity.(T))
+		},
+	}
+}
+
+var errPageFetched = errors.New("page fetched")
+
+// listEntities gets all the pages, or only the page of params.Page if it is set
+func listEntities[T any](
+	s skalinsdk.Skalin,
+	params *skalinsdk.GetParams,
+	list func(skalinsdk.Skalin, *skalinsdk.GetParams) ([]T, error),
+	walk func(skalinsdk.Skalin, *skalinsdk.GetParams, func([]T) error) error,
+) ([]interface{}, error) {
+	if params == nil || params.Page == nil {
+		entities, err := list(s, params)
+		return toInterfaces(entities), err
+	}
+	var entities []T
+	err := walk(s, params, func(page []T) error {
+		entities = page
+		return errPageFetched
+	})
+	if err != nil && !errors.Is(err, errPageFetched) {
+		return nil, err
+	}
+	return toInterfaces(entities), nil
+}
+
+func toInterfaces[T any](items []T) []interface{} {
+	result := make([]interface{}, len(items))
+	for i := range items {
+		result[i] = items[i]
+	}
+	return result
+}
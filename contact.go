@@ -4,11 +4,30 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
 	"time"
 )
 
 type CustomAttributes map[string]interface{}
 
+// customAttributesFromJSON returns the fields of the JSON object which are not fields of the entity
+func customAttributesFromJSON(b []byte, entity interface{}) (CustomAttributes, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf(entity)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		delete(fields, name)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return CustomAttributes(fields), nil
+}
+
 type Contact struct {
 	Id               string           `json:"id,omitempty"`
 	CustomerId       *string          `json:"customerId,omitempty"` // correspond to the customer Id
@@ -26,7 +45,6 @@ type Contact struct {
 
 // need custom MarshalJSON to merge custom attributes with contact
 // see the doc of skalin
-// for now, no need to create a custom UnmarshalJson because skalin API does not return custom attributes
 func (c Contact) MarshalJSON() ([]byte, error) {
 	type Alias Contact // prevent stack overflow
 	if c.CustomAttributes == nil {
@@ -52,6 +70,23 @@ func (c Contact) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r)
 }
 
+// UnmarshalJSON keeps the fields which are not fields of Contact in CustomAttributes.
+// The client then only keeps the custom attributes of the account, see WithCustomAttributes
+func (c *Contact) UnmarshalJSON(b []byte) error {
+	type Alias Contact // prevent stack overflow
+	var alias Alias
+	if err := json.Unmarshal(b, &alias); err != nil {
+		return err
+	}
+	customAttributes, err := customAttributesFromJSON(b, alias)
+	if err != nil {
+		return err
+	}
+	*c = Contact(alias)
+	c.CustomAttributes = customAttributes
+	return nil
+}
+
 const (
 	SAVE_CONTACT_PATH            = "/contacts"
 	UPDATE_CONTACT_PATH          = "/contacts/%v"
@@ -80,6 +115,11 @@ func (s *skalinAPI) GetContacts(params *GetParams) ([]Contact, error) {
 	return getEntities[[]Contact](s, SAVE_CONTACT_PATH, buildQueryParamsFromGetParams(params))
 }
 
+// WalkContacts calls fn with the contacts of each page, it stops at the first error returned by fn
+func (s *skalinAPI) WalkContacts(params *GetParams, fn func([]Contact) error) error {
+	return walkEntities[[]Contact](s, SAVE_CONTACT_PATH, buildQueryParamsFromGetParams(params), fn)
+}
+
 func (s *skalinAPI) CreateContactForCustomer(contact Contact, customerId string) (*Contact, error) {
 	return save(s, fmt.Sprintf(CREATE_CUSTOMER_CONTACT_PATH, customerId), contact)
 }
@@ -89,10 +129,10 @@ func (s *skalinAPI) DeleteContact(contact Contact) error {
 		return fmt.Errorf("contact id is empty")
 	}
 	// for now the API does not return the updated agreement
-	r, b, err := s.api.DeleteData(BuildUrl(fmt.Sprintf(UPDATE_CONTACT_PATH, contact.Id)), "", nil, nil, nil, http.StatusOK)
-	s.api.GetLogger().Infof("Delete contact %+v: %v", r, string(b))
+	_, _, err := s.api.DeleteData(BuildUrl(fmt.Sprintf(UPDATE_CONTACT_PATH, contact.Id)), "", nil, nil, nil, http.StatusOK)
 	if err != nil {
 		return err
 	}
+	loggerOf(s.api).Log(LevelDebug, "contact deleted", Fields{"id": contact.Id})
 	return nil
 }
@@ -33,6 +33,48 @@ func TestCustomMarshaller(t *testing.T) {
 	assert.Equal(t, customAttribute["customAttribute2"], result["customAttribute2"])
 }
 
+func TestCustomUnmarshaller(t *testing.T) {
+	var contact Contact
+	err := json.Unmarshal([]byte(`{"id":"1","firstName":"Mon super prenom","tags":["tag1"],"customAttribute1":"customValue1"}`), &contact)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "1", contact.Id)
+	assert.Equal(t, "Mon super prenom", contact.FirstName)
+	assert.Equal(t, []string{"tag1"}, contact.Tags)
+	assert.Equal(t, CustomAttributes{"customAttribute1": "customValue1"}, contact.CustomAttributes)
+
+	contact = Contact{}
+	err = json.Unmarshal([]byte(`{"id":"1"}`), &contact)
+	assert.NoError(t, err)
+	assert.Nil(t, contact.CustomAttributes)
+}
+
+func TestContactRoundTrip(t *testing.T) {
+	// the fields of the server which are not custom attributes of the account are not sent back
+	response := []byte(`{"status":"success","data":[{"id":"1","refId":"2","email":"a@karnott.fr","plan":"pro",
+		"createdAt":"2023-01-01T00:00:00Z","owner":{"id":"3"}}]}`)
+	mockApi := new(MockAPI)
+	mockApi.On("send", http.MethodGet, BuildUrl(SAVE_CONTACT_PATH), jsonContentType,
+		mock.Anything, mock.Anything, mock.Anything, http.StatusOK).Return(nil, response, nil).Once()
+	var sent map[string]interface{}
+	mockApi.On("send", http.MethodPatch, BuildUrl(fmt.Sprintf(UPDATE_CONTACT_PATH, "1")), jsonContentType,
+		mock.Anything, mock.MatchedBy(func(b []byte) bool { return json.Unmarshal(b, &sent) == nil }),
+		mock.Anything, http.StatusOK).Return(nil, []byte(`{"status":"success"}`), nil).Once()
+
+	skalinAPI := &skalinAPI{api: mockApi, customAttributes: map[string]bool{"plan": true}}
+	contacts, err := skalinAPI.GetContacts(nil)
+	if !assert.NoError(t, err) || !assert.Len(t, contacts, 1) {
+		return
+	}
+	assert.Equal(t, CustomAttributes{"plan": "pro"}, contacts[0].CustomAttributes)
+	_, err = skalinAPI.UpdateContact(contacts[0])
+	mockApi.AssertExpectations(t)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"id": "1", "refId": "2", "email": "a@karnott.fr", "plan": "pro"}, sent)
+	}
+}
+
 func TestGetContacts(t *testing.T) {
 	var fakeContactsData = `[
   {
@@ -236,6 +278,28 @@ func TestSaveContact(t *testing.T) {
 		assert.Equal(t, contact.LastName, contactSaved.LastName)
 		assert.Equal(t, contact.Phone, contactSaved.Phone)
 	})
+
+	t.Run("Replay", func(t *testing.T) {
+		skalinApi, err := newCassetteClient(t, "save_contact")
+		if !assert.NoError(t, err) {
+			return
+		}
+		contact := Contact{
+			RefId:     "cassette-contact-1",
+			LastName:  "Ceci est un test de l'API (nom de famille)",
+			FirstName: "Ceci est un test de l'API (prénom)",
+			Email:     "contact+testapi@karnott.fr",
+		}
+		contactSaved, err := skalinApi.SaveContact(contact)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NotEqual(t, "", contactSaved.Id)
+		assert.Equal(t, contact.RefId, contactSaved.RefId)
+
+		_, err = skalinApi.SaveContact(Contact{RefId: contact.RefId, Email: contact.Email})
+		assert.EqualError(t, err, "Contact must have a firstName or a lastName")
+	})
 }
 
 func TestCreateContactForCustomer(t *testing.T) {
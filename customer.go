@@ -3,6 +3,7 @@ package skalinsdk
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 )
 
@@ -10,7 +11,7 @@ type Customer struct {
 	Id               string           `json:"id,omitempty"`
 	RefId            string           `json:"refId,omitempty"`
 	Name             string           `json:"name,omitempty"`
-	Stage            string           `json:"stage,omitempty"`
+	Stage            CustomerStage    `json:"stage,omitempty"`
 	Tags             []string         `json:"tags,omitempty"`
 	LastActivityTs   *time.Time       `json:"lastActivityTs,omitempty"`
 	CustomAttributes CustomAttributes `json:"-"`
@@ -23,7 +24,6 @@ type CustomerResponse struct {
 
 // need custom MarshalJSON to merge custom attributes with contact
 // see the doc of skalin
-// for now, no need to create a custom UnmarshalJson because skalin API does not return custom attributes
 func (c Customer) MarshalJSON() ([]byte, error) {
 	type Alias Customer // prevent stack overflow
 	if c.CustomAttributes == nil {
@@ -49,8 +49,26 @@ func (c Customer) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r)
 }
 
+// UnmarshalJSON keeps the fields which are not fields of Customer in CustomAttributes.
+// The client then only keeps the custom attributes of the account, see WithCustomAttributes
+func (c *Customer) UnmarshalJSON(b []byte) error {
+	type Alias Customer // prevent stack overflow
+	var alias Alias
+	if err := json.Unmarshal(b, &alias); err != nil {
+		return err
+	}
+	customAttributes, err := customAttributesFromJSON(b, alias)
+	if err != nil {
+		return err
+	}
+	*c = Customer(alias)
+	c.CustomAttributes = customAttributes
+	return nil
+}
+
 const (
-	SAVE_CUSTOMER_PATH = "/customers"
+	SAVE_CUSTOMER_PATH   = "/customers"
+	UPDATE_CUSTOMER_PATH = "/customers/%v"
 )
 
 func (s *skalinAPI) SaveCustomer(customer Customer) (*Customer, error) {
@@ -60,3 +78,32 @@ func (s *skalinAPI) SaveCustomer(customer Customer) (*Customer, error) {
 func (s *skalinAPI) GetCustomers(params *GetParams) ([]Customer, error) {
 	return getEntities[[]Customer](s, SAVE_CUSTOMER_PATH, buildQueryParamsFromGetParams(params))
 }
+
+func (s *skalinAPI) UpdateCustomer(customer Customer) (*Customer, error) {
+	if customer.Id == "" {
+		return nil, fmt.Errorf("customer id is empty")
+	}
+	// for now the API does not return the updated customer
+	err := update(s, fmt.Sprintf(UPDATE_CUSTOMER_PATH, customer.Id), customer)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (s *skalinAPI) DeleteCustomer(customer Customer) error {
+	if customer.Id == "" {
+		return fmt.Errorf("customer id is empty")
+	}
+	_, _, err := s.api.DeleteData(BuildUrl(fmt.Sprintf(UPDATE_CUSTOMER_PATH, customer.Id)), "", nil, nil, nil, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	loggerOf(s.api).Log(LevelDebug, "customer deleted", Fields{"id": customer.Id})
+	return nil
+}
+
+// WalkCustomers calls fn with the customers of each page, it stops at the first error returned by fn
+func (s *skalinAPI) WalkCustomers(params *GetParams, fn func([]Customer) error) error {
+	return walkEntities[[]Customer](s, SAVE_CUSTOMER_PATH, buildQueryParamsFromGetParams(params), fn)
+}
@@ -33,6 +33,16 @@ func TestCustomCustomerMarshaller(t *testing.T) {
 	assert.Equal(t, customAttribute["customAttribute2"], result["customAttribute2"])
 }
 
+func TestCustomCustomerUnmarshaller(t *testing.T) {
+	var customer Customer
+	err := json.Unmarshal([]byte(`{"id":"1","name":"Mon super nom","customAttribute1":12}`), &customer)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Mon super nom", customer.Name)
+	assert.Equal(t, CustomAttributes{"customAttribute1": float64(12)}, customer.CustomAttributes)
+}
+
 func TestSaveCustomer(t *testing.T) {
 	var fakeCustomerData = `{
 		"id": "",
@@ -232,3 +242,114 @@ func TestGetCustomers(t *testing.T) {
 		}
 	})
 }
+
+func TestUpdateCustomer(t *testing.T) {
+	var fakeCustomerResponse = `
+	{
+		"status":"success",
+	}`
+	customer := Customer{
+		Id:    "12345",
+		RefId: "2",
+		Name:  "Mon super client",
+		Stage: "Customer",
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		expectedBody := []byte(fakeCustomerResponse)
+		_expectedCustomer, err := json.Marshal(customer)
+		if !assert.NoError(t, err) {
+			return
+		}
+		mockApi.On(
+			"send",
+			http.MethodPatch,
+			BuildUrl(fmt.Sprintf(UPDATE_CUSTOMER_PATH, customer.Id)),
+			jsonContentType,
+			mock.Anything,
+			_expectedCustomer,
+			mock.Anything,
+			http.StatusOK,
+		).Return(nil, expectedBody, nil)
+
+		skalinAPI := &skalinAPI{api: mockApi}
+		updated, err := skalinAPI.UpdateCustomer(customer)
+		mockApi.AssertExpectations(t)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, customer.Name, updated.Name)
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		mockApi.On(
+			"send",
+			http.MethodPatch,
+			BuildUrl(fmt.Sprintf(UPDATE_CUSTOMER_PATH, customer.Id)),
+			jsonContentType,
+			mock.Anything,
+			mock.Anything,
+			mock.Anything,
+			http.StatusOK,
+		).Return(nil, nil, fmt.Errorf("Status code != %v: %v", http.StatusInternalServerError, http.StatusOK))
+
+		skalinAPI := &skalinAPI{api: mockApi}
+		updated, err := skalinAPI.UpdateCustomer(customer)
+		assert.Error(t, err)
+		assert.Nil(t, updated)
+
+		_, err = skalinAPI.UpdateCustomer(Customer{RefId: "2"})
+		assert.EqualError(t, err, "customer id is empty")
+	})
+}
+
+func TestDeleteCustomer(t *testing.T) {
+	var fakeCustomerResponse = `
+	{
+		"status":"success",
+	}`
+
+	t.Run("OK", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		expectedBody := []byte(fakeCustomerResponse)
+
+		customerId := "1"
+		mockApi.On(
+			"send",
+			http.MethodDelete,
+			BuildUrl(fmt.Sprintf(UPDATE_CUSTOMER_PATH, customerId)),
+			"",
+			mock.Anything,
+			[]byte(nil),
+			mock.Anything,
+			http.StatusOK,
+		).Return(nil, expectedBody, nil)
+
+		skalinAPI := &skalinAPI{api: mockApi}
+		err := skalinAPI.DeleteCustomer(Customer{Id: customerId})
+		mockApi.AssertExpectations(t)
+		assert.NoError(t, err)
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		mockApi := new(MockAPI)
+
+		customerId := "1"
+		mockApi.On(
+			"send",
+			http.MethodDelete,
+			BuildUrl(fmt.Sprintf(UPDATE_CUSTOMER_PATH, customerId)),
+			"",
+			mock.Anything,
+			[]byte(nil),
+			mock.Anything,
+			http.StatusOK,
+		).Return(nil, nil, fmt.Errorf("Status code != %v: %v", http.StatusInternalServerError, http.StatusOK))
+
+		skalinAPI := &skalinAPI{api: mockApi}
+		err := skalinAPI.DeleteCustomer(Customer{Id: customerId})
+		assert.Error(t, err)
+	})
+}
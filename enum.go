@@ -0,0 +1,132 @@
+package skalinsdk
+
+import (
+	"fmt"
+)
+
+// EnumMode is the validation of the enum values (periods, types, stages...) of the entities sent to Skalin.
+// The values read from Skalin are always kept, even the unknown ones
+type EnumMode int
+
+const (
+	// EnumStrict rejects the unknown values, so a typo is caught before the request is sent
+	EnumStrict EnumMode = iota
+	// EnumLenient sends the unknown values, like values added by Skalin after this version of the SDK
+	EnumLenient
+)
+
+// EnumError is returned when an entity with an unknown enum value is sent in strict mode
+type EnumError struct {
+	Type  string
+	Value string
+}
+
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("unknown %v %q", e.Type, e.Value)
+}
+
+// Period is the unit of the engagement and notice of an agreement
+type Period string
+
+const (
+	PeriodDay   Period = "DAY"
+	PeriodWeek  Period = "WEEK"
+	PeriodMonth Period = "MONTH"
+	PeriodYear  Period = "YEAR"
+)
+
+func (p Period) IsValid() bool {
+	switch p {
+	case PeriodDay, PeriodWeek, PeriodMonth, PeriodYear:
+		return true
+	}
+	return false
+}
+
+// AgreementType is the type of an agreement. Only INITIAL is documented by Skalin,
+// the other types are sent with EnumLenient
+type AgreementType string
+
+const (
+	AgreementTypeInitial AgreementType = "INITIAL"
+)
+
+func (t AgreementType) IsValid() bool {
+	return t == AgreementTypeInitial
+}
+
+// CustomerStage is a stage of the customer lifecycle. The stages are defined in each Skalin account,
+// so they are only validated once set with WithCustomerStages
+type CustomerStage string
+
+// TagType is the type of a tag. The tags are only read, so their type and entity are not validated
+type TagType string
+
+const (
+	TagTypeCustom TagType = "CUSTOM"
+	TagTypeManual TagType = "MANUAL"
+)
+
+// TagEntity is the entity a tag can be put on
+type TagEntity string
+
+const (
+	TagEntityContact  TagEntity = "CONTACT"
+	TagEntityCustomer TagEntity = "CUSTOMER"
+)
+
+type enum interface {
+	~string
+	IsValid() bool
+}
+
+// enumValidator checks the enum values of the entities sent by a client, its zero value is strict without stages
+type enumValidator struct {
+	mode   EnumMode
+	stages map[CustomerStage]bool
+}
+
+func newEnumValidator(mode EnumMode, stages []CustomerStage) enumValidator {
+	v := enumValidator{mode: mode}
+	if len(stages) > 0 {
+		v.stages = make(map[CustomerStage]bool, len(stages))
+		for _, stage := range stages {
+			v.stages[stage] = true
+		}
+	}
+	return v
+}
+
+// validStage returns true if the stage is set on the client, or if no stage is set
+func (v enumValidator) validStage(stage CustomerStage) bool {
+	return v.stages == nil || v.stages[stage]
+}
+
+// check returns an *EnumError for the first unknown value of the entity, the empty values are unset fields
+func (v enumValidator) check(entity interface{}) error {
+	if v.mode == EnumLenient {
+		return nil
+	}
+	switch e := entity.(type) {
+	case Agreement:
+		if err := checkEnum("period", e.EngagementPeriod); err != nil {
+			return err
+		}
+		if err := checkEnum("period", e.NoticePeriod); err != nil {
+			return err
+		}
+		return checkEnum("agreement type", e.Type)
+	case Customer:
+		if e.Stage != "" && !v.validStage(e.Stage) {
+			return &EnumError{Type: "customer stage", Value: string(e.Stage)}
+		}
+	}
+	return nil
+}
+
+func checkEnum[T enum](name string, value T) error {
+	if value == "" || value.IsValid() {
+		return nil
+	}
+	return &EnumError{Type: name, Value: string(value)}
+}
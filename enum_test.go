@@ -0,0 +1,82 @@
+package skalinsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnumJSON(t *testing.T) {
+	agreement := Agreement{EngagementPeriod: PeriodYear, NoticePeriod: PeriodMonth, Type: AgreementTypeInitial}
+	b, err := json.Marshal(agreement)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.JSONEq(t, `{"engagementPeriod":"YEAR","noticePeriod":"MONTH","type":"INITIAL"}`, string(b))
+
+	// the unknown values read from Skalin are kept
+	var tag Tag
+	err = json.Unmarshal([]byte(`{"id":"1","type":"CUSTOM","entity":"DEAL"}`), &tag)
+	if assert.NoError(t, err) {
+		assert.Equal(t, TagTypeCustom, tag.Type)
+		assert.Equal(t, TagEntity("DEAL"), tag.Entity)
+	}
+	err = json.Unmarshal([]byte(`{"engagementPeriod":"QUARTER","type":"TRIAL"}`), &agreement)
+	if assert.NoError(t, err) {
+		assert.Equal(t, Period("QUARTER"), agreement.EngagementPeriod)
+		assert.Equal(t, AgreementType("TRIAL"), agreement.Type)
+	}
+}
+
+func TestEnumValidator(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		v := newEnumValidator(EnumStrict, nil)
+		assert.NoError(t, v.check(Agreement{EngagementPeriod: PeriodYear}))
+		assert.NoError(t, v.check(Agreement{Type: AgreementTypeInitial}))
+		// any stage is valid until the stages of the account are set
+		assert.NoError(t, v.check(Customer{Stage: "Onboarding"}))
+
+		v = newEnumValidator(EnumStrict, []CustomerStage{"Onboarding", "Customer"})
+		assert.NoError(t, v.check(Customer{Stage: "Customer"}))
+		assert.NoError(t, v.check(Customer{}))
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		err := newEnumValidator(EnumStrict, nil).check(Agreement{NoticePeriod: "QUARTER"})
+		var enumErr *EnumError
+		if assert.True(t, errors.As(err, &enumErr), "error %v", err) {
+			assert.Equal(t, "period", enumErr.Type)
+			assert.Equal(t, "QUARTER", enumErr.Value)
+		}
+
+		// only INITIAL is documented by Skalin
+		err = newEnumValidator(EnumStrict, nil).check(Agreement{Type: "UPSELL"})
+		assert.EqualError(t, err, `unknown agreement type "UPSELL"`)
+
+		err = newEnumValidator(EnumStrict, []CustomerStage{"Onboarding"}).check(Customer{Stage: "Churned"})
+		assert.EqualError(t, err, `unknown customer stage "Churned"`)
+	})
+
+	t.Run("Lenient", func(t *testing.T) {
+		v := newEnumValidator(EnumLenient, []CustomerStage{"Onboarding"})
+		assert.NoError(t, v.check(Agreement{EngagementPeriod: "QUARTER", Type: "UPSELL"}))
+		assert.NoError(t, v.check(Customer{Stage: "Churned"}))
+	})
+}
+
+func TestSaveWithUnknownEnum(t *testing.T) {
+	// the entity is rejected before the request is sent
+	mockApi := new(MockAPI)
+	skalinAPI := &skalinAPI{api: mockApi}
+	_, err := skalinAPI.SaveAgreement(Agreement{RefId: "1", EngagementPeriod: "QUARTER"})
+	assert.EqualError(t, err, `unknown period "QUARTER"`)
+	_, err = skalinAPI.UpdateAgreement(Agreement{Id: "1", NoticePeriod: "quarter"})
+	assert.EqualError(t, err, `unknown period "quarter"`)
+
+	skalinAPI.enums = newEnumValidator(EnumStrict, []CustomerStage{"Onboarding"})
+	_, err = skalinAPI.SaveCustomer(Customer{RefId: "1", Stage: "Churned"})
+	assert.EqualError(t, err, `unknown customer stage "Churned"`)
+	mockApi.AssertExpectations(t)
+}
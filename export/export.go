@@ -0,0 +1,293 @@
+// Package export dumps the Skalin entities (customers, contacts, agreements and tags) to CSV or JSON Lines files.
+//
+// The entities are read page by page from the list endpoints and each page is written before the next one is requested,
+// so the memory stays flat for large accounts. A CSV export with the default columns keeps the rows in a temporary file
+// until the last page, so its header has the custom attributes of all the entities.
+// The custom attributes are the ones read by the client, see skalinsdk.WithCustomAttributes.
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/internal/records"
+)
+
+type Format = records.Format
+
+const (
+	FormatCSV   = records.FormatCSV
+	FormatJSONL = records.FormatJSONL
+)
+
+type Entity string
+
+const (
+	Customers  Entity = "customers"
+	Contacts   Entity = "contacts"
+	Agreements Entity = "agreements"
+	Tags       Entity = "tags"
+)
+
+// Entities are all the entities exported by ExportAll
+var Entities = []Entity{Customers, Contacts, Agreements, Tags}
+
+// Row is an exported entity, by column
+type Row map[string]string
+
+const (
+	// CustomAttributePrefix is the prefix of the columns of the custom attributes, like `customAttributes.plan`
+	CustomAttributePrefix = "customAttributes."
+	// TagsSeparator joins the tags of a contact or a customer in a single column
+	TagsSeparator = ";"
+	// dates of the agreements have no time
+	dateLayout = time.DateOnly
+)
+
+// DefaultColumns are the columns of the standard fields of each entity
+var DefaultColumns = map[Entity][]string{
+	Customers:  {"id", "refId", "name", "stage", "tags", "lastActivityTs"},
+	Contacts:   {"id", "refId", "customerId", "customer", "email", "firstName", "lastName", "phone", "npsScore", "tags", "lastActivityTs"},
+	Agreements: {"id", "refId", "customerId", "customer", "type", "plan", "startDate", "endDate", "renewalDate", "autoRenew", "engagement", "engagementPeriod", "notice", "noticePeriod", "mrr", "fee"},
+	Tags:       {"id", "name", "type", "entity", "color"},
+}
+
+type Options struct {
+	Format Format // if empty, ExportFile guesses the format from the extension of the file, Export uses CSV
+	// Columns are the columns written, in order. If empty, the CSV format has the default columns of the entity
+	// and all the custom attributes found; the JSON Lines format has all the fields of each entity
+	Columns []string
+	// Params are sent to the list endpoint, to filter the entities server side or to set the page size.
+	// The page of the params is ignored, all the pages are exported
+	Params *skalinsdk.GetParams
+	// Filter keeps the rows for which it returns true. All the rows are kept if it is nil
+	Filter     func(Row) bool
+	TimeLayout string         // layout of the timestamps, like lastActivityTs
+	Location   *time.Location // location of the timestamps
+}
+
+var DefaultOptions = Options{
+	TimeLayout: time.RFC3339,
+	Location:   time.UTC,
+}
+
+type Report struct {
+	Pages    int
+	Exported int
+	Filtered int // rows removed by the filter
+}
+
+// Export writes all the entities of the given type in w.
+// It returns the report of the rows written so far with the error
+func Export(client skalinsdk.Skalin, entity Entity, w io.Writer, opts Options) (*Report, error) {
+	if opts.Format == "" {
+		opts.Format = FormatCSV
+	}
+	if opts.TimeLayout == "" {
+		opts.TimeLayout = DefaultOptions.TimeLayout
+	}
+	if opts.Location == nil {
+		opts.Location = DefaultOptions.Location
+	}
+	if _, ok := DefaultColumns[entity]; !ok {
+		return nil, fmt.Errorf("unknown entity %q", entity)
+	}
+	e := &exporter{entity: entity, w: w, opts: opts, report: &Report{}}
+	if err := e.open(); err != nil {
+		return e.report, err
+	}
+	defer e.close()
+	params := pageParams(opts.Params)
+	var err error
+	switch entity {
+	case Customers:
+		err = client.WalkCustomers(params, func(customers []skalinsdk.Customer) error {
+			return writePage(e, customers, e.customerRow)
+		})
+	case Contacts:
+		err = client.WalkContacts(params, func(contacts []skalinsdk.Contact) error {
+			return writePage(e, contacts, e.contactRow)
+		})
+	case Agreements:
+		err = client.WalkAgreements(params, func(agreements []skalinsdk.Agreement) error {
+			return writePage(e, agreements, e.agreementRow)
+		})
+	case Tags:
+		err = client.WalkTags(params, func(tags []skalinsdk.Tag) error {
+			return writePage(e, tags, e.tagRow)
+		})
+	}
+	if err != nil {
+		return e.report, fmt.Errorf("error to export %v: %w", entity, err)
+	}
+	return e.report, e.finish()
+}
+
+// ExportFile writes all the entities of the given type in the file at path, which is created or truncated
+func ExportFile(client skalinsdk.Skalin, entity Entity, path string, opts Options) (*Report, error) {
+	if opts.Format == "" {
+		format, err := records.FormatFromPath(path)
+		if err != nil {
+			return nil, err
+		}
+		opts.Format = format
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	report, err := Export(client, entity, file, opts)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("error to close %v: %w", path, closeErr)
+	}
+	return report, err
+}
+
+// ExportAll writes each entity in its own file of dir, like `customers.csv`.
+// The columns of the options are ignored, each file has the default columns of its entity
+func ExportAll(client skalinsdk.Skalin, dir string, opts Options) (map[Entity]*Report, error) {
+	if opts.Format == "" {
+		opts.Format = FormatCSV
+	}
+	opts.Columns = nil
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	reports := make(map[Entity]*Report, len(Entities))
+	for _, entity := range Entities {
+		report, err := ExportFile(client, entity, filepath.Join(dir, string(entity)+"."+string(opts.Format)), opts)
+		reports[entity] = report
+		if err != nil {
+			return reports, err
+		}
+	}
+	return reports, nil
+}
+
+// pageParams copies the params without the page, so the params of the caller are not modified
+func pageParams(params *skalinsdk.GetParams) *skalinsdk.GetParams {
+	if params == nil {
+		return nil
+	}
+	copied := *params
+	copied.Page = nil
+	return &copied
+}
+
+type exporter struct {
+	entity Entity
+	w      io.Writer
+	opts   Options
+	report *Report
+	writer records.Writer
+	// spool keeps the rows of a CSV export with the default columns, until all the custom attributes are known
+	spool         *os.File
+	customColumns map[string]bool
+}
+
+func writePage[T any](e *exporter, entities []T, toRow func(T) Row) error {
+	e.report.Pages++
+	rows := make([]Row, len(entities))
+	for i, entity := range entities {
+		rows[i] = toRow(entity)
+	}
+	for _, row := range rows {
+		if e.opts.Filter != nil && !e.opts.Filter(row) {
+			e.report.Filtered++
+			continue
+		}
+		if e.customColumns != nil {
+			for _, column := range customAttributeColumns([]Row{row}) {
+				e.customColumns[column] = true
+			}
+		}
+		if err := e.writer.Write(row); err != nil {
+			return fmt.Errorf("error to write row: %w", err)
+		}
+		e.report.Exported++
+	}
+	return e.writer.Flush()
+}
+
+// open writes the header in w, or creates the spool of a CSV export with the default columns
+func (e *exporter) open() error {
+	out, format, columns := e.w, e.opts.Format, e.opts.Columns
+	if len(columns) == 0 && format == FormatCSV {
+		spool, err := os.CreateTemp("", "skalin-export-*.jsonl")
+		if err != nil {
+			return fmt.Errorf("error to create the spool of the export: %w", err)
+		}
+		e.spool, e.customColumns = spool, make(map[string]bool)
+		out, format, columns = spool, FormatJSONL, nil
+	}
+	writer, err := records.NewWriter(out, format, columns)
+	if err != nil {
+		return err
+	}
+	e.writer = writer
+	return nil
+}
+
+// finish copies the spooled rows in w, with the default columns and all the custom attributes found
+func (e *exporter) finish() error {
+	if e.spool == nil {
+		return nil
+	}
+	custom := make([]string, 0, len(e.customColumns))
+	for column := range e.customColumns {
+		custom = append(custom, column)
+	}
+	sort.Strings(custom)
+	writer, err := records.NewWriter(e.w, FormatCSV, append(append([]string{}, DefaultColumns[e.entity]...), custom...))
+	if err != nil {
+		return err
+	}
+	if _, err := e.spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error to read the spool of the export: %w", err)
+	}
+	reader, err := records.NewReader(e.spool, FormatJSONL)
+	if err != nil {
+		return err
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error to read the spool of the export: %w", err)
+		}
+		if err := writer.Write(record.Fields); err != nil {
+			return fmt.Errorf("error to write row: %w", err)
+		}
+	}
+	return writer.Flush()
+}
+
+func (e *exporter) close() {
+	if e.spool != nil {
+		e.spool.Close()
+		os.Remove(e.spool.Name())
+	}
+}
+
+func customAttributeColumns(rows []Row) []string {
+	seen := make(map[string]bool)
+	columns := make([]string, 0)
+	for _, row := range rows {
+		for column := range row {
+			if strings.HasPrefix(column, CustomAttributePrefix) && !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
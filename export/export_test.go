@@ -0,0 +1,125 @@
+package export
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T, server *skalintest.Server) skalinsdk.Skalin {
+	client, err := skalinsdk.New("clientId", "clientApiId", "clientApiSecret",
+		skalinsdk.WithHTTPClient(server.Client()),
+		skalinsdk.WithLogger(skalinsdk.NewNopLogger()),
+		skalinsdk.WithCustomAttributes("plan", "seats", "region"),
+	)
+	assert.NoError(t, err)
+	return client
+}
+
+func seed(t *testing.T, server *skalintest.Server, kind skalintest.Kind, entities ...skalintest.Entity) {
+	for _, entity := range entities {
+		_, err := server.Seed(kind, entity)
+		assert.NoError(t, err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server)
+	seed(t, server, skalintest.Customers,
+		skalintest.Entity{"refId": "1", "name": "Karnott", "tags": []string{"a", "b"}, "lastActivityTs": "2023-01-02T03:04:05+01:00", "plan": "pro"},
+		skalintest.Entity{"refId": "2", "name": "Skalin", "seats": 3},
+		skalintest.Entity{"refId": "3", "name": "Other", "region": "north"},
+	)
+	size := 2
+
+	t.Run("CSV", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		report, err := Export(client, Customers, buf, Options{Params: &skalinsdk.GetParams{Size: &size}})
+		if !assert.NoError(t, err) {
+			return
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if !assert.Len(t, lines, 4) {
+			return
+		}
+		// the custom attribute of the second page is in the header
+		assert.Equal(t, "id,refId,name,stage,tags,lastActivityTs,customAttributes.plan,customAttributes.region,customAttributes.seats", lines[0])
+		assert.Contains(t, lines[1], ",1,Karnott,,a;b,2023-01-02T02:04:05Z,pro,,")
+		assert.Contains(t, lines[2], ",2,Skalin,,,,,,3")
+		assert.Contains(t, lines[3], ",3,Other,,,,,north,")
+		assert.Equal(t, Report{Pages: 2, Exported: 3}, *report)
+	})
+
+	t.Run("JSONL with filter", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		report, err := Export(client, Customers, buf, Options{
+			Format: FormatJSONL,
+			Filter: func(row Row) bool { return row["refId"] != "2" },
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if !assert.Len(t, lines, 2) {
+			return
+		}
+		assert.Contains(t, lines[1], `"customAttributes.region":"north"`)
+		assert.Equal(t, 2, report.Exported)
+		assert.Equal(t, 1, report.Filtered)
+	})
+
+	t.Run("Dates and columns", func(t *testing.T) {
+		seed(t, server, skalintest.Agreements, skalintest.Entity{"refId": "a1", "startDate": "2023-01-01", "mrr": 100, "autoRenew": true})
+		buf := &bytes.Buffer{}
+		_, err := Export(client, Agreements, buf, Options{Columns: []string{"refId", "startDate", "endDate", "autoRenew", "mrr"}})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "refId,startDate,endDate,autoRenew,mrr\na1,2023-01-01,,true,100\n", buf.String())
+	})
+
+	t.Run("Without entity", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		report, err := Export(client, Tags, buf, DefaultOptions)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "id,name,type,entity,color\n", buf.String())
+		assert.Equal(t, 0, report.Exported)
+	})
+
+	t.Run("All", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "dump")
+		reports, err := ExportAll(client, dir, Options{Format: FormatJSONL})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 3, reports[Customers].Exported)
+		assert.Equal(t, 1, reports[Agreements].Exported)
+		b, err := os.ReadFile(filepath.Join(dir, "customers.jsonl"))
+		assert.NoError(t, err)
+		assert.Equal(t, 3, strings.Count(string(b), "\n"))
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		_, err := Export(client, "unknown", &bytes.Buffer{}, DefaultOptions)
+		assert.Error(t, err)
+		_, err = ExportFile(client, Customers, filepath.Join(t.TempDir(), "customers.xml"), DefaultOptions)
+		assert.Error(t, err)
+
+		server.InjectFault("GET /v1/customers", skalintest.Fault{Status: http.StatusServiceUnavailable, Times: 1})
+		defer server.ClearFaults()
+		report, err := Export(client, Customers, &bytes.Buffer{}, DefaultOptions)
+		assert.Error(t, err)
+		assert.Equal(t, 0, report.Exported)
+	})
+}
@@ -0,0 +1,134 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+)
+
+func (e *exporter) customerRow(c skalinsdk.Customer) Row {
+	row := Row{
+		"id":             c.Id,
+		"refId":          c.RefId,
+		"name":           c.Name,
+		"stage":          string(c.Stage),
+		"tags":           strings.Join(c.Tags, TagsSeparator),
+		"lastActivityTs": e.formatTime(c.LastActivityTs),
+	}
+	addCustomAttributes(row, c.CustomAttributes)
+	return row
+}
+
+func (e *exporter) contactRow(c skalinsdk.Contact) Row {
+	row := Row{
+		"id":             c.Id,
+		"refId":          c.RefId,
+		"customerId":     formatString(c.CustomerId),
+		"customer":       formatString(c.Customer),
+		"email":          c.Email,
+		"firstName":      c.FirstName,
+		"lastName":       c.LastName,
+		"phone":          c.Phone,
+		"npsScore":       formatInt(c.NpsScore),
+		"tags":           strings.Join(c.Tags, TagsSeparator),
+		"lastActivityTs": e.formatTime(c.LastActivityTs),
+	}
+	addCustomAttributes(row, c.CustomAttributes)
+	return row
+}
+
+func (e *exporter) agreementRow(a skalinsdk.Agreement) Row {
+	return Row{
+		"id":               a.Id,
+		"refId":            a.RefId,
+		"customerId":       formatString(a.CustomerId),
+		"customer":         formatString(a.Customer),
+		"type":             string(a.Type),
+		"plan":             a.Plan,
+		"startDate":        formatDate(a.StartDate),
+		"endDate":          formatDate(a.EndDate),
+		"renewalDate":      formatDate(a.RenewalDate),
+		"autoRenew":        strconv.FormatBool(a.AutoRenew),
+		"engagement":       formatInt(a.Engagement),
+		"engagementPeriod": string(a.EngagementPeriod),
+		"notice":           formatInt(a.Notice),
+		"noticePeriod":     string(a.NoticePeriod),
+		"mrr":              formatMoney(a.Mrr),
+		"fee":              formatMoney(a.Fee),
+	}
+}
+
+func (e *exporter) tagRow(t skalinsdk.Tag) Row {
+	return Row{
+		"id":     t.Id,
+		"name":   t.Name,
+		"type":   string(t.Type),
+		"entity": string(t.Entity),
+		"color":  t.Color,
+	}
+}
+
+func (e *exporter) formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.In(e.opts.Location).Format(e.opts.TimeLayout)
+}
+
+func formatDate(d *skalinsdk.SkalinDate) string {
+	if d == nil {
+		return ""
+	}
+	return time.Time(*d).Format(dateLayout)
+}
+
+func formatString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatMoney(m *skalinsdk.Money) string {
+	if m == nil {
+		return ""
+	}
+	return m.Decimal()
+}
+
+func formatInt(i *int) string {
+	if i == nil {
+		return ""
+	}
+	return strconv.Itoa(*i)
+}
+
+// addCustomAttributes flattens the custom attributes in `customAttributes.<key>` columns.
+// Nested values are written as JSON
+func addCustomAttributes(row Row, attributes skalinsdk.CustomAttributes) {
+	for key, value := range attributes {
+		row[CustomAttributePrefix+key] = formatValue(value)
+	}
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool, int, int64, json.Number:
+		return fmt.Sprintf("%v", v)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(b)
+}
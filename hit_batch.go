@@ -0,0 +1,178 @@
+package skalinsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type HitBatchOptions struct {
+	Concurrency  int           // number of hits sent in parallel
+	MaxRetries   int           // number of retries after the first attempt of a hit
+	RetryBackoff time.Duration // delay before the first retry, doubled on each new retry
+}
+
+var DefaultHitBatchOptions = HitBatchOptions{
+	Concurrency:  4,
+	MaxRetries:   3,
+	RetryBackoff: 500 * time.Millisecond,
+}
+
+type HitResult struct {
+	Index     int // index of the hit in the batch
+	EventID   string
+	Attempts  int
+	Duplicate bool // the hit was not sent because the tracker already sent the same event
+	Response  *http.Response
+	Body      []byte
+	Err       error
+}
+
+func (r HitResult) status() HitStatus {
+	switch {
+	case r.Duplicate:
+		return HitDropped
+	case r.Err != nil:
+		return HitFailed
+	}
+	return HitSent
+}
+
+type HitResults []HitResult
+
+// Failed returns the indexes of the hits which were not sent,
+// sorted in ascending order so a backfill can resume from the first one
+func (r HitResults) Failed() []int {
+	failed := make([]int, 0)
+	for _, result := range r {
+		if result.Err != nil {
+			failed = append(failed, result.Index)
+		}
+	}
+	sort.Ints(failed)
+	return failed
+}
+
+// HitValidationError is returned by HitBatch when at least one hit is invalid.
+// In this case, no hit of the batch is sent
+type HitValidationError struct {
+	Errors map[int]error // validation error by index of the hit in the batch
+}
+
+func (e *HitValidationError) Error() string {
+	indexes := make([]int, 0, len(e.Errors))
+	for index := range e.Errors {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	messages := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		messages = append(messages, fmt.Sprintf("hit %v: %v", index, e.Errors[index]))
+	}
+	return fmt.Sprintf("%v invalid hit(s): %v", len(indexes), strings.Join(messages, "; "))
+}
+
+func (a skalinTracker) HitBatch(hits []HitTrack) (HitResults, error) {
+	return a.HitBatchWithOptions(hits, DefaultHitBatchOptions)
+}
+
+// HitBatchWithOptions validates all the hits before sending any of them,
+// then sends them with a bounded concurrency and retries the failed ones.
+// The returned error is not nil if the batch is invalid or if some hits are still failing after retries;
+// the results give the status of each hit
+func (a skalinTracker) HitBatchWithOptions(hits []HitTrack, opts HitBatchOptions) (HitResults, error) {
+	a.hitQueued(len(hits))
+	validationErrors := make(map[int]error)
+	for i, ht := range hits {
+		if err := validateHit(ht); err != nil {
+			validationErrors[i] = err
+		}
+	}
+	if len(validationErrors) > 0 {
+		for range hits {
+			a.hitDone(HitDropped, 0)
+		}
+		return nil, &HitValidationError{Errors: validationErrors}
+	}
+	if a.api.GetClientID() == nil {
+		for range hits {
+			a.hitDone(HitFailed, 0)
+		}
+		return nil, fmt.Errorf("client_id is not set")
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	// event ids are set before sending, so the retries of a hit keep the same id
+	hits = append([]HitTrack(nil), hits...)
+	for i := range hits {
+		hits[i] = hits[i].WithEventID()
+	}
+
+	results := make(HitResults, len(hits))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = a.sendHitWithRetry(i, hits[i], opts)
+				a.hitDone(results[i].status(), results[i].Attempts)
+			}
+		}()
+	}
+	for i := range hits {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if failed := results.Failed(); len(failed) > 0 {
+		return results, fmt.Errorf("%v of %v hits failed, first failed hit is at index %v", len(failed), len(hits), failed[0])
+	}
+	return results, nil
+}
+
+func (a skalinTracker) sendHitWithRetry(index int, ht HitTrack, opts HitBatchOptions) HitResult {
+	result := HitResult{Index: index, EventID: *ht.EventID}
+	backoff := opts.RetryBackoff
+	for {
+		result.Attempts++
+		result.Response, result.Body, result.Err = a.sendHit(ht)
+		if errors.Is(result.Err, ErrDuplicateHit) {
+			result.Duplicate = true
+			result.Err = nil
+			return result
+		}
+		// no need to wait while the circuit breaker is open
+		if result.Err == nil || result.Attempts > opts.MaxRetries || errors.Is(result.Err, ErrCircuitOpen) || !isRetryableHitError(result.Response, result.Err) {
+			return result
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// a hit is retried if the collect endpoint was not reached because of a network error,
+// or if it answered with a server or rate limit error. The other errors, like a cancelled context, are not retried
+func isRetryableHitError(res *http.Response, err error) bool {
+	if res != nil {
+		return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr)
+}
@@ -0,0 +1,170 @@
+package skalinsdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestHit(visitorID string) HitTrack {
+	return HitTrack{
+		Action:    HitActionEvent,
+		VisitorID: visitorID,
+		VisitID:   "1234567890123456",
+		Identity: HitIdentity{
+			ID: sPtr("test"),
+		},
+		Event: &HitEvent{
+			Name:      "test",
+			EventName: "test",
+		},
+	}
+}
+
+var testHitBatchOptions = HitBatchOptions{
+	Concurrency: 2,
+	MaxRetries:  2,
+}
+
+func TestHitBatch(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		mockApi.On(
+			"send",
+			http.MethodPost,
+			SKALIN_HIT_URL,
+			formURLEncodedContentType,
+			mock.Anything,
+			mock.Anything,
+			mock.Anything,
+			http.StatusOK,
+		).Return(nil, nil, nil).Times(3)
+
+		skalinTracker := &skalinTracker{api: mockApi}
+		results, err := skalinTracker.HitBatchWithOptions([]HitTrack{
+			newTestHit("1111111111111111"),
+			newTestHit("2222222222222222"),
+			newTestHit("3333333333333333"),
+		}, testHitBatchOptions)
+		mockApi.AssertExpectations(t)
+		if !assert.NoError(t, err) || !assert.Len(t, results, 3) {
+			return
+		}
+		for i, result := range results {
+			assert.Equal(t, i, result.Index)
+			assert.Equal(t, 1, result.Attempts)
+		}
+		assert.Empty(t, results.Failed())
+	})
+
+	t.Run("With invalid hits", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		invalidHit := newTestHit("too short")
+		skalinTracker := &skalinTracker{api: mockApi}
+		results, err := skalinTracker.HitBatchWithOptions([]HitTrack{
+			newTestHit("1111111111111111"),
+			invalidHit,
+			newTestHit("3333333333333333"),
+			invalidHit,
+		}, testHitBatchOptions)
+		mockApi.AssertNotCalled(t, "send")
+		assert.Nil(t, results)
+		var validationErr *HitValidationError
+		if !assert.ErrorAs(t, err, &validationErr) {
+			return
+		}
+		assert.Len(t, validationErr.Errors, 2)
+		assert.Contains(t, validationErr.Errors, 1)
+		assert.Contains(t, validationErr.Errors, 3)
+	})
+
+	t.Run("With retry", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		mockApi.On(
+			"send",
+			http.MethodPost,
+			SKALIN_HIT_URL,
+			formURLEncodedContentType,
+			mock.Anything,
+			mock.Anything,
+			mock.Anything,
+			http.StatusOK,
+		).Return(nil, nil, &url.Error{Op: "Post", URL: SKALIN_HIT_URL, Err: errors.New("connection reset")}).Once()
+		mockApi.On(
+			"send",
+			http.MethodPost,
+			SKALIN_HIT_URL,
+			formURLEncodedContentType,
+			mock.Anything,
+			mock.Anything,
+			mock.Anything,
+			http.StatusOK,
+		).Return(nil, nil, nil).Once()
+
+		skalinTracker := &skalinTracker{api: mockApi}
+		results, err := skalinTracker.HitBatchWithOptions([]HitTrack{newTestHit("1111111111111111")}, testHitBatchOptions)
+		mockApi.AssertExpectations(t)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 2, results[0].Attempts)
+	})
+
+	t.Run("Not retried", func(t *testing.T) {
+		// only the network errors are retried, not the errors before the request is sent nor a cancelled request
+		for _, sendErr := range []error{
+			errors.New("error to marshal identity"),
+			&url.Error{Op: "Post", URL: SKALIN_HIT_URL, Err: context.Canceled},
+		} {
+			mockApi := new(MockAPI)
+			mockApi.On(
+				"send",
+				http.MethodPost,
+				SKALIN_HIT_URL,
+				formURLEncodedContentType,
+				mock.Anything,
+				mock.Anything,
+				mock.Anything,
+				http.StatusOK,
+			).Return(nil, nil, sendErr).Once()
+
+			skalinTracker := &skalinTracker{api: mockApi}
+			results, err := skalinTracker.HitBatchWithOptions([]HitTrack{newTestHit("1111111111111111")}, testHitBatchOptions)
+			assert.Error(t, err)
+			mockApi.AssertExpectations(t)
+			assert.Equal(t, 1, results[0].Attempts, sendErr.Error())
+		}
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		mockApi.On(
+			"send",
+			http.MethodPost,
+			SKALIN_HIT_URL,
+			formURLEncodedContentType,
+			mock.Anything,
+			mock.Anything,
+			mock.Anything,
+			http.StatusOK,
+		).Return(&http.Response{StatusCode: http.StatusBadRequest}, nil, errors.New("bad request"))
+
+		skalinTracker := &skalinTracker{api: mockApi}
+		results, err := skalinTracker.HitBatchWithOptions([]HitTrack{
+			newTestHit("1111111111111111"),
+			newTestHit("2222222222222222"),
+		}, testHitBatchOptions)
+		if !assert.Error(t, err) {
+			return
+		}
+		// a client error is not retried
+		mockApi.AssertNumberOfCalls(t, "send", 2)
+		assert.Equal(t, []int{0, 1}, results.Failed())
+		assert.Equal(t, 1, results[1].Attempts)
+	})
+}
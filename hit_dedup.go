@@ -0,0 +1,107 @@
+package skalinsdk
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// number of event ids remembered by a tracker to not send the same event twice
+const DefaultHitDedupWindow = 10000
+
+var ErrDuplicateHit = errors.New("hit with the same event id was already sent")
+
+// buildEventID derives a 16 chars id from the visitor, the event (with its properties) and the timestamp of the hit,
+// so the same logical event always gets the same id
+func buildEventID(ht HitTrack, ts time.Time) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%v|%v|%v", ht.VisitorID, ht.Action, ts.UTC().UnixNano())
+	if ht.Event != nil {
+		fmt.Fprintf(h, "|%v|%v|", ht.Event.Name, ht.Event.EventName)
+		// the keys of a map are sorted by json.Marshal, and the properties were validated so they can be marshalled.
+		// Nil and empty properties are the same event
+		if len(ht.Event.Properties) > 0 {
+			properties, _ := json.Marshal(ht.Event.Properties)
+			h.Write(properties)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// WithEventID sets the timestamp and the event id of the hit if the caller did not give them.
+// It must be called once before the first attempt and the hit returned must be reused,
+// so retries and replays keep the same timestamp and id
+func (ht HitTrack) WithEventID() HitTrack {
+	if ht.Ts == nil {
+		ts := time.Now()
+		ht.Ts = &ts
+	}
+	if ht.EventID == nil {
+		eventID := buildEventID(ht, *ht.Ts)
+		ht.EventID = &eventID
+	}
+	return ht
+}
+
+// hitDedup is a bounded window of the event ids sent (or being sent) by a tracker.
+// When the window is full, the oldest id is forgotten
+type hitDedup struct {
+	mu       sync.Mutex
+	size     int
+	sent     *list.List // event ids sent, oldest first
+	elements map[string]*list.Element
+	inFlight map[string]bool
+}
+
+func newHitDedup(size int) *hitDedup {
+	return &hitDedup{
+		size:     size,
+		sent:     list.New(),
+		elements: make(map[string]*list.Element),
+		inFlight: make(map[string]bool),
+	}
+}
+
+// reserve returns false if the event id was already sent or is being sent
+func (d *hitDedup) reserve(eventID string) bool {
+	if d == nil {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.elements[eventID]; ok || d.inFlight[eventID] {
+		return false
+	}
+	d.inFlight[eventID] = true
+	return true
+}
+
+// release must be called when the hit was not sent, so it can be retried
+func (d *hitDedup) release(eventID string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.inFlight, eventID)
+}
+
+func (d *hitDedup) confirm(eventID string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.inFlight, eventID)
+	d.elements[eventID] = d.sent.PushBack(eventID)
+	for d.sent.Len() > d.size {
+		oldest := d.sent.Front()
+		d.sent.Remove(oldest)
+		delete(d.elements, oldest.Value.(string))
+	}
+}
@@ -0,0 +1,147 @@
+package skalinsdk
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBuildEventID(t *testing.T) {
+	ts := time.Date(2023, 7, 14, 12, 0, 0, 0, time.UTC)
+	hit := newTestHit("1111111111111111")
+	eventID := buildEventID(hit, ts)
+	assert.Len(t, eventID, 16)
+	assert.Equal(t, eventID, buildEventID(hit, ts.In(time.FixedZone("UTC+2", 2*3600))))
+	assert.NotEqual(t, eventID, buildEventID(hit, ts.Add(time.Second)))
+	assert.NotEqual(t, eventID, buildEventID(newTestHit("2222222222222222"), ts))
+	hit.Event.EventName = "other"
+	assert.NotEqual(t, eventID, buildEventID(hit, ts))
+
+	// events at the same time which differ by their properties
+	hit = newTestHit("1111111111111111")
+	hit.Event.Properties = EventProperties{"feature_id": "map", "count": 1}
+	withProperties := buildEventID(hit, ts)
+	assert.NotEqual(t, eventID, withProperties)
+	hit.Event.Properties = EventProperties{"count": 1, "feature_id": "map"}
+	assert.Equal(t, withProperties, buildEventID(hit, ts))
+	hit.Event.Properties["feature_id"] = "export"
+	assert.NotEqual(t, withProperties, buildEventID(hit, ts))
+
+	// nil and empty properties are the same event
+	hit.Event.Properties = nil
+	withoutProperties := buildEventID(hit, ts)
+	hit.Event.Properties = EventProperties{}
+	assert.Equal(t, withoutProperties, buildEventID(hit, ts))
+}
+
+func TestWithEventID(t *testing.T) {
+	hit := newTestHit("1111111111111111").WithEventID()
+	if !assert.NotNil(t, hit.Ts) || !assert.NotNil(t, hit.EventID) {
+		return
+	}
+	// the hit returned keeps the same timestamp and id
+	again := hit.WithEventID()
+	assert.Equal(t, hit.Ts, again.Ts)
+	assert.Equal(t, *hit.EventID, *again.EventID)
+	assert.Equal(t, buildEventID(hit, *hit.Ts), *hit.EventID)
+}
+
+func TestHitDedup(t *testing.T) {
+	mockHitSend := func(mockApi *MockAPI) *mock.Call {
+		return mockApi.On(
+			"send",
+			http.MethodPost,
+			SKALIN_HIT_URL,
+			formURLEncodedContentType,
+			mock.Anything,
+			mock.Anything,
+			mock.Anything,
+			http.StatusOK,
+		)
+	}
+	ts := time.Date(2023, 7, 14, 12, 0, 0, 0, time.UTC)
+	newHit := func(visitorID string) HitTrack {
+		hit := newTestHit(visitorID)
+		hit.Ts = &ts
+		return hit
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		mockHitSend(mockApi).Return(nil, nil, nil)
+
+		skalinTracker := &skalinTracker{api: mockApi, dedup: newHitDedup(DefaultHitDedupWindow)}
+		_, _, err := skalinTracker.Hit(newHit("1111111111111111"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, _, err = skalinTracker.Hit(newHit("1111111111111111"))
+		assert.ErrorIs(t, err, ErrDuplicateHit)
+		mockApi.AssertNumberOfCalls(t, "send", 1)
+		data := mockApi.Calls[0].Arguments.Get(5).(*url.Values)
+		assert.Equal(t, buildEventID(newHit("1111111111111111"), ts), data.Get("event_id"))
+	})
+
+	t.Run("Failed hit can be sent again", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		mockHitSend(mockApi).Return(nil, nil, errors.New("timeout")).Once()
+		mockHitSend(mockApi).Return(nil, nil, nil).Once()
+
+		skalinTracker := &skalinTracker{api: mockApi, dedup: newHitDedup(DefaultHitDedupWindow)}
+		_, _, err := skalinTracker.Hit(newHit("1111111111111111"))
+		assert.Error(t, err)
+		_, _, err = skalinTracker.Hit(newHit("1111111111111111"))
+		assert.NoError(t, err)
+		mockApi.AssertExpectations(t)
+	})
+
+	t.Run("Retries keep the same event id", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		mockHitSend(mockApi).Return(nil, nil, &url.Error{Op: "Post", URL: SKALIN_HIT_URL, Err: errors.New("timeout")}).Once()
+		mockHitSend(mockApi).Return(nil, nil, nil).Once()
+
+		hit := newTestHit("1111111111111111") // without timestamp
+		skalinTracker := &skalinTracker{api: mockApi, dedup: newHitDedup(DefaultHitDedupWindow)}
+		results, err := skalinTracker.HitBatchWithOptions([]HitTrack{hit}, HitBatchOptions{Concurrency: 1, MaxRetries: 1})
+		if !assert.NoError(t, err) {
+			return
+		}
+		mockApi.AssertExpectations(t)
+		first := mockApi.Calls[0].Arguments.Get(5).(*url.Values).Get("event_id")
+		assert.Equal(t, first, mockApi.Calls[1].Arguments.Get(5).(*url.Values).Get("event_id"))
+		assert.Equal(t, first, results[0].EventID)
+		assert.Equal(t, 2, results[0].Attempts)
+		assert.False(t, results[0].Duplicate)
+	})
+
+	t.Run("Duplicates in batch", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		mockHitSend(mockApi).Return(nil, nil, nil).Once()
+
+		skalinTracker := &skalinTracker{api: mockApi, dedup: newHitDedup(DefaultHitDedupWindow)}
+		results, err := skalinTracker.HitBatchWithOptions([]HitTrack{newHit("1111111111111111"), newHit("1111111111111111")}, HitBatchOptions{Concurrency: 1})
+		if !assert.NoError(t, err) {
+			return
+		}
+		mockApi.AssertExpectations(t)
+		assert.False(t, results[0].Duplicate)
+		assert.True(t, results[1].Duplicate)
+		assert.Empty(t, results.Failed())
+	})
+
+	t.Run("Bounded window", func(t *testing.T) {
+		dedup := newHitDedup(2)
+		for _, eventID := range []string{"a", "b", "c"} {
+			assert.True(t, dedup.reserve(eventID))
+			dedup.confirm(eventID)
+		}
+		assert.True(t, dedup.reserve("a"))
+		assert.False(t, dedup.reserve("a"))
+		assert.False(t, dedup.reserve("c"))
+	})
+}
@@ -0,0 +1,415 @@
+// Package importer loads customers, contacts and agreements from CSV or JSON Lines files into Skalin.
+//
+// All the rows are validated before anything is written: a file with an invalid row is not imported at all.
+// The valid rows are then saved with a bounded concurrency, and the outcome of each row can be written in a results file.
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/internal/records"
+)
+
+type Format = records.Format
+
+const (
+	FormatCSV   = records.FormatCSV
+	FormatJSONL = records.FormatJSONL
+)
+
+type Entity string
+
+const (
+	Customers  Entity = "customers"
+	Contacts   Entity = "contacts"
+	Agreements Entity = "agreements"
+)
+
+type Outcome string
+
+const (
+	OutcomeSaved   Outcome = "saved"
+	OutcomeFailed  Outcome = "failed"
+	OutcomeInvalid Outcome = "invalid"
+	OutcomeSkipped Outcome = "skipped" // valid row not saved because other rows are invalid
+)
+
+var (
+	ErrInvalidRows = errors.New("invalid rows, nothing was imported")
+	ErrFailedRows  = errors.New("rows failed to be saved")
+)
+
+// Result is the outcome of a row of the file
+type Result struct {
+	Line    int // line of the file where the row starts
+	RefID   string
+	Outcome Outcome
+	ID      string // id of the saved entity
+	Err     error
+}
+
+// resultColumns are the columns of the results file
+var resultColumns = []string{"line", "refId", "outcome", "id", "error"}
+
+type Options struct {
+	Format Format // if empty, the format is guessed from the extension of the file
+	// Mapping gives the column of each field. If nil, the columns named like the fields
+	// (and the `customAttributes.<key>` columns) are imported, which is the format of the export package
+	Mapping       Mapping
+	Concurrency   int    // number of rows saved at the same time
+	ResultsPath   string // if set, the result of each row is written in this CSV or JSON Lines file
+	TagsSeparator string
+	DateLayout    string // layout of the dates of the agreements
+	TimeLayout    string // layout of the timestamps, like lastActivityTs
+	// Currency of the amounts, like mrr. It must be the account currency of the client, see skalinsdk.WithAccountCurrency
+	Currency skalinsdk.Currency
+	// EnumMode and CustomerStages validate the enum values of the rows, they must be the options of the client,
+	// see skalinsdk.WithEnumMode and skalinsdk.WithCustomerStages
+	EnumMode       skalinsdk.EnumMode
+	CustomerStages []skalinsdk.CustomerStage
+}
+
+var DefaultOptions = Options{
+	Concurrency:   4,
+	TagsSeparator: ";",
+	DateLayout:    time.DateOnly,
+	TimeLayout:    time.RFC3339,
+	Currency:      skalinsdk.CurrencyEUR,
+}
+
+type Report struct {
+	Read    int
+	Saved   int
+	Failed  int
+	Invalid int
+	Results []Result // by line
+}
+
+// pendingRow is a valid row, ready to be saved
+type pendingRow struct {
+	line  int
+	refID string
+	save  func(client skalinsdk.Skalin) (string, error)
+}
+
+// Run imports the entities of the source file.
+// It returns ErrInvalidRows without writing anything if a row is invalid, and ErrFailedRows if a row can't be saved
+func Run(client skalinsdk.Skalin, entity Entity, source string, opts Options) (*Report, error) {
+	opts = withDefaults(opts)
+	if opts.Mapping != nil {
+		if err := opts.Mapping.check(entity); err != nil {
+			return nil, err
+		}
+	} else if _, ok := fields[entity]; !ok {
+		return nil, fmt.Errorf("unknown entity %q", entity)
+	}
+	format := opts.Format
+	if format == "" {
+		var err error
+		format, err = records.FormatFromPath(source)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var resultsFormat Format
+	if opts.ResultsPath != "" {
+		var err error
+		resultsFormat, err = records.FormatFromPath(opts.ResultsPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := readRecords(source, format)
+	if err != nil {
+		return nil, err
+	}
+	mapping := opts.Mapping
+	if mapping == nil {
+		mapping = defaultMapping(entity, recordColumns(rows))
+	}
+
+	report := &Report{Read: len(rows), Results: make([]Result, len(rows))}
+	pending, err := validate(client, entity, mapping, rows, opts, report)
+	if err != nil {
+		return report, err
+	}
+	if report.Invalid == 0 {
+		save(client, pending, opts.Concurrency, report)
+	}
+	if opts.ResultsPath != "" {
+		if err := writeResults(opts.ResultsPath, resultsFormat, report.Results); err != nil {
+			return report, err
+		}
+	}
+	switch {
+	case report.Invalid > 0:
+		return report, fmt.Errorf("%w: %v invalid rows on %v", ErrInvalidRows, report.Invalid, report.Read)
+	case report.Failed > 0:
+		return report, fmt.Errorf("%w: %v failed rows on %v", ErrFailedRows, report.Failed, report.Read)
+	}
+	return report, nil
+}
+
+func withDefaults(opts Options) Options {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = DefaultOptions.Concurrency
+	}
+	if opts.TagsSeparator == "" {
+		opts.TagsSeparator = DefaultOptions.TagsSeparator
+	}
+	if opts.DateLayout == "" {
+		opts.DateLayout = DefaultOptions.DateLayout
+	}
+	if opts.TimeLayout == "" {
+		opts.TimeLayout = DefaultOptions.TimeLayout
+	}
+	if opts.Currency == "" {
+		opts.Currency = DefaultOptions.Currency
+	}
+	return opts
+}
+
+// readRecords reads all the file, because all the rows are validated before saving the first one
+func readRecords(source string, format Format) ([]*records.Record, error) {
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader, err := records.NewReader(file, format)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]*records.Record, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, record)
+	}
+}
+
+// recordColumns returns the columns of all the records, the keys of the JSON Lines records can differ between lines
+func recordColumns(rows []*records.Record) []string {
+	seen := make(map[string]bool)
+	columns := make([]string, 0)
+	for _, row := range rows {
+		for column := range row.Fields {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+	return columns
+}
+
+// validate converts the rows to entities, with the customer refIds resolved to customer ids.
+// The invalid rows are reported, and the valid rows are reported as skipped until they are saved
+func validate(client skalinsdk.Skalin, entity Entity, mapping Mapping, rows []*records.Record, opts Options, report *Report) ([]pendingRow, error) {
+	values := make([]map[string]interface{}, len(rows))
+	needCustomers := false
+	for i, record := range rows {
+		v, err := mapping.values(entity, record, opts)
+		report.Results[i] = Result{Line: record.FileLine, RefID: strings.TrimSpace(record.Fields[mapping["refId"]]), Outcome: OutcomeSkipped, Err: err}
+		if err != nil {
+			report.Results[i].Outcome = OutcomeInvalid
+			report.Invalid++
+			continue
+		}
+		values[i] = v
+		if _, ok := v["customerId"]; !ok && v["customer"] != nil {
+			needCustomers = true
+		}
+	}
+
+	var customerIDs map[string]string
+	if needCustomers {
+		var err error
+		customerIDs, err = loadCustomerIDs(client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pending := make([]pendingRow, 0, len(rows))
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		row, err := newPendingRow(entity, v, customerIDs)
+		if err != nil {
+			report.Results[i].Outcome = OutcomeInvalid
+			report.Results[i].Err = err
+			report.Invalid++
+			continue
+		}
+		row.line = rows[i].FileLine
+		row.refID = report.Results[i].RefID
+		pending = append(pending, row)
+	}
+	return pending, nil
+}
+
+// loadCustomerIDs returns the ids of the customers by refId
+func loadCustomerIDs(client skalinsdk.Skalin) (map[string]string, error) {
+	ids := make(map[string]string)
+	err := client.WalkCustomers(nil, func(customers []skalinsdk.Customer) error {
+		for _, customer := range customers {
+			if _, ok := ids[customer.RefId]; !ok && customer.RefId != "" {
+				ids[customer.RefId] = customer.Id
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error to get customers: %w", err)
+	}
+	return ids, nil
+}
+
+func newPendingRow(entity Entity, values map[string]interface{}, customerIDs map[string]string) (pendingRow, error) {
+	var customerID string
+	if entity == Contacts || entity == Agreements {
+		customerID, _ = values["customerId"].(string)
+		if refID, _ := values["customer"].(string); customerID == "" && refID != "" {
+			customerID = customerIDs[refID]
+			if customerID == "" {
+				return pendingRow{}, fmt.Errorf("customer %v not found", refID)
+			}
+		}
+		if customerID == "" && entity == Agreements {
+			return pendingRow{}, errors.New("field customerId or customer is required")
+		}
+		// the customer is given by the path of the save
+		delete(values, "customerId")
+		delete(values, "customer")
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return pendingRow{}, err
+	}
+	switch entity {
+	case Customers:
+		var customer skalinsdk.Customer
+		if err := json.Unmarshal(b, &customer); err != nil {
+			return pendingRow{}, err
+		}
+		return pendingRow{save: func(client skalinsdk.Skalin) (string, error) {
+			saved, err := client.SaveCustomer(customer)
+			return savedID(saved, err, func(c *skalinsdk.Customer) string { return c.Id })
+		}}, nil
+	case Contacts:
+		var contact skalinsdk.Contact
+		if err := json.Unmarshal(b, &contact); err != nil {
+			return pendingRow{}, err
+		}
+		return pendingRow{save: func(client skalinsdk.Skalin) (string, error) {
+			var saved *skalinsdk.Contact
+			var err error
+			if customerID != "" {
+				saved, err = client.CreateContactForCustomer(contact, customerID)
+			} else {
+				saved, err = client.SaveContact(contact)
+			}
+			return savedID(saved, err, func(c *skalinsdk.Contact) string { return c.Id })
+		}}, nil
+	}
+	var agreement skalinsdk.Agreement
+	if err := json.Unmarshal(b, &agreement); err != nil {
+		return pendingRow{}, err
+	}
+	return pendingRow{save: func(client skalinsdk.Skalin) (string, error) {
+		saved, err := client.CreateAgreementForCustomer(agreement, customerID)
+		return savedID(saved, err, func(a *skalinsdk.Agreement) string { return a.Id })
+	}}, nil
+}
+
+func savedID[T any](saved *T, err error, id func(*T) string) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if saved == nil {
+		return "", nil
+	}
+	return id(saved), nil
+}
+
+// save saves the rows with at most concurrency rows at the same time
+func save(client skalinsdk.Skalin, rows []pendingRow, concurrency int, report *Report) {
+	index := make(map[int]int, len(report.Results))
+	for i, result := range report.Results {
+		index[result.Line] = i
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	semaphore := make(chan struct{}, concurrency)
+	for _, row := range rows {
+		row := row
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			id, err := row.save(client)
+			mu.Lock()
+			defer mu.Unlock()
+			result := &report.Results[index[row.line]]
+			result.ID = id
+			result.Err = err
+			if err != nil {
+				result.Outcome = OutcomeFailed
+				report.Failed++
+				return
+			}
+			result.Outcome = OutcomeSaved
+			report.Saved++
+		}()
+	}
+	wg.Wait()
+}
+
+func writeResults(path string, format Format, results []Result) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("error to close %v: %w", path, closeErr)
+		}
+	}()
+	writer, err := records.NewWriter(file, format, resultColumns)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		fields := map[string]string{
+			"line":    strconv.Itoa(result.Line),
+			"refId":   result.RefID,
+			"outcome": string(result.Outcome),
+			"id":      result.ID,
+		}
+		if result.Err != nil {
+			fields["error"] = result.Err.Error()
+		}
+		if err := writer.Write(fields); err != nil {
+			return fmt.Errorf("error to write results: %w", err)
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,155 @@
+package importer
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T, server *skalintest.Server) skalinsdk.Skalin {
+	client, err := skalinsdk.New("clientId", "clientApiId", "clientApiSecret",
+		skalinsdk.WithHTTPClient(server.Client()),
+		skalinsdk.WithLogger(skalinsdk.NewNopLogger()),
+	)
+	assert.NoError(t, err)
+	return client
+}
+
+// customerByRefID returns the customer of the server with the refId, the rows are not saved in order
+func customerByRefID(server *skalintest.Server, refID string) skalintest.Entity {
+	for _, customer := range server.Entities(skalintest.Customers) {
+		if customer["refId"] == refID {
+			return customer
+		}
+	}
+	return nil
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+const testCustomers = `Code,Company,Labels,Region
+c1,Karnott,a;b,north
+c2,Skalin,,south
+`
+
+func TestRun(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server)
+	mapping := Mapping{"refId": "Code", "name": "Company", "tags": "Labels", "customAttributes.region": "Region"}
+
+	t.Run("OK", func(t *testing.T) {
+		results := filepath.Join(t.TempDir(), "results.csv")
+		report, err := Run(client, Customers, writeFile(t, "customers.csv", testCustomers), Options{Mapping: mapping, ResultsPath: results})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 2, report.Saved)
+		if !assert.Len(t, server.Entities(skalintest.Customers), 2) {
+			return
+		}
+		c1, c2 := customerByRefID(server, "c1"), customerByRefID(server, "c2")
+		assert.Equal(t, "Karnott", c1["name"])
+		assert.Equal(t, []interface{}{"a", "b"}, c1["tags"])
+		assert.Equal(t, "north", c1["region"])
+
+		b, err := os.ReadFile(results)
+		assert.NoError(t, err)
+		assert.Equal(t, "line,refId,outcome,id,error\n2,c1,saved,"+c1["id"].(string)+",\n3,c2,saved,"+c2["id"].(string)+",\n", string(b))
+	})
+
+	t.Run("Customer refId", func(t *testing.T) {
+		contacts := writeFile(t, "contacts.jsonl", `{"refId":"u1","email":"u1@karnott.fr","customer":"c1","npsScore":"9"}`+"\n")
+		report, err := Run(client, Contacts, contacts, DefaultOptions)
+		if !assert.NoError(t, err) || !assert.Equal(t, 1, report.Saved) {
+			return
+		}
+		saved := server.Entity(skalintest.Contacts, report.Results[0].ID)
+		assert.Equal(t, customerByRefID(server, "c1")["id"], saved["customerId"])
+		assert.Equal(t, float64(9), saved["npsScore"])
+
+		agreements := writeFile(t, "agreements.csv", "refId,customer,startDate,mrr,autoRenew\na1,c2,2023-01-01,100,true\n")
+		report, err = Run(client, Agreements, agreements, DefaultOptions)
+		if !assert.NoError(t, err) {
+			return
+		}
+		saved = server.Entity(skalintest.Agreements, report.Results[0].ID)
+		assert.Equal(t, customerByRefID(server, "c2")["id"], saved["customerId"])
+		assert.Equal(t, "2023-01-01", saved["startDate"])
+	})
+
+	t.Run("Invalid rows", func(t *testing.T) {
+		before := len(server.Entities(skalintest.Agreements))
+		results := filepath.Join(t.TempDir(), "results.jsonl")
+		source := writeFile(t, "agreements.csv", "refId,customer,startDate,mrr\na2,c1,2023-01-01,100\na3,unknown,2023-01-01,100\na4,c1,01/01/2023,100\na5,c1,2023-01-01,ten\n")
+		report, err := Run(client, Agreements, source, Options{ResultsPath: results})
+		assert.True(t, errors.Is(err, ErrInvalidRows))
+		assert.Equal(t, 3, report.Invalid)
+		assert.Equal(t, OutcomeSkipped, report.Results[0].Outcome)
+		assert.EqualError(t, report.Results[1].Err, "customer unknown not found")
+		assert.Equal(t, OutcomeInvalid, report.Results[2].Outcome)
+		assert.Len(t, server.Entities(skalintest.Agreements), before)
+
+		b, err := os.ReadFile(results)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, strings.Count(string(b), "\n"))
+		assert.Contains(t, string(b), `"outcome":"invalid"`)
+	})
+
+	t.Run("Invalid enums", func(t *testing.T) {
+		before := len(server.Entities(skalintest.Agreements))
+		source := writeFile(t, "agreements.csv", "refId,customer,startDate,engagementPeriod,type\n"+
+			"a6,c1,2023-01-01,YEAR,INITIAL\na7,c1,2023-01-01,QUARTER,\na8,c1,2023-01-01,,UPSELL\n")
+		report, err := Run(client, Agreements, source, DefaultOptions)
+		assert.True(t, errors.Is(err, ErrInvalidRows))
+		assert.Equal(t, 2, report.Invalid)
+		assert.EqualError(t, report.Results[1].Err, `field engagementPeriod (column engagementPeriod): unknown period "QUARTER"`)
+		assert.EqualError(t, report.Results[2].Err, `field type (column type): unknown agreement type "UPSELL"`)
+		assert.Len(t, server.Entities(skalintest.Agreements), before)
+
+		customers := writeFile(t, "customers.csv", "refId,name,stage\nc3,Other,Churned\n")
+		report, err = Run(client, Customers, customers, Options{CustomerStages: []skalinsdk.CustomerStage{"Onboarding"}})
+		assert.True(t, errors.Is(err, ErrInvalidRows))
+		assert.EqualError(t, report.Results[0].Err, `field stage (column stage): unknown customer stage "Churned"`)
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		_, err := Run(client, Customers, writeFile(t, "customers.csv", testCustomers), Options{Mapping: Mapping{"unknown": "Code"}})
+		assert.Error(t, err)
+		_, err = Run(client, Agreements, writeFile(t, "agreements.csv", testCustomers), Options{Mapping: Mapping{"customAttributes.region": "Region"}})
+		assert.Error(t, err)
+		_, err = Run(client, "tags", writeFile(t, "tags.csv", testCustomers), DefaultOptions)
+		assert.Error(t, err)
+
+		server.InjectFault("POST /v1/customers", skalintest.Fault{Status: http.StatusInternalServerError, Times: 1})
+		defer server.ClearFaults()
+		report, err := Run(client, Customers, writeFile(t, "customers.csv", testCustomers), Options{Mapping: mapping, Concurrency: 1})
+		assert.True(t, errors.Is(err, ErrFailedRows))
+		assert.Equal(t, 1, report.Failed)
+		assert.Equal(t, 1, report.Saved)
+		assert.Equal(t, OutcomeFailed, report.Results[0].Outcome)
+	})
+}
+
+func TestLoadMapping(t *testing.T) {
+	mapping, err := LoadMapping(writeFile(t, "mapping.json", `{"refId":"Code","customAttributes.region":"Region"}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Mapping{"refId": "Code", "customAttributes.region": "Region"}, mapping)
+	assert.NoError(t, mapping.check(Customers))
+
+	_, err = LoadMapping(writeFile(t, "mapping.json", `[]`))
+	assert.Error(t, err)
+}
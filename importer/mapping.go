@@ -0,0 +1,211 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/internal/records"
+)
+
+// CustomAttributePrefix is the prefix of the fields of the custom attributes, like `customAttributes.plan`
+const CustomAttributePrefix = "customAttributes."
+
+// Mapping gives the column (or JSON key) of the file used for each field of the entity.
+// The fields are the JSON names of the fields of the entity, like `refId`, or `customAttributes.<key>` for a custom attribute
+type Mapping map[string]string
+
+// LoadMapping reads a mapping from a JSON file, like `{"refId": "Customer code", "customAttributes.region": "Region"}`
+func LoadMapping(path string) (Mapping, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var mapping Mapping
+	if err := json.Unmarshal(b, &mapping); err != nil {
+		return nil, fmt.Errorf("error to unmarshal mapping %v: %w", path, err)
+	}
+	return mapping, nil
+}
+
+type fieldType int
+
+const (
+	stringField fieldType = iota
+	intField
+	boolField
+	tagsField
+	dateField
+	timeField
+	moneyField
+	periodField
+	agreementTypeField
+	stageField
+)
+
+var fields = map[Entity]map[string]fieldType{
+	Customers: {
+		"refId":          stringField,
+		"name":           stringField,
+		"stage":          stageField,
+		"tags":           tagsField,
+		"lastActivityTs": timeField,
+	},
+	Contacts: {
+		"refId":          stringField,
+		"customerId":     stringField,
+		"customer":       stringField,
+		"email":          stringField,
+		"firstName":      stringField,
+		"lastName":       stringField,
+		"phone":          stringField,
+		"npsScore":       intField,
+		"tags":           tagsField,
+		"lastActivityTs": timeField,
+	},
+	Agreements: {
+		"refId":            stringField,
+		"customerId":       stringField,
+		"customer":         stringField,
+		"type":             agreementTypeField,
+		"plan":             stringField,
+		"startDate":        dateField,
+		"endDate":          dateField,
+		"renewalDate":      dateField,
+		"autoRenew":        boolField,
+		"engagement":       intField,
+		"engagementPeriod": periodField,
+		"notice":           intField,
+		"noticePeriod":     periodField,
+		"mrr":              moneyField,
+		"fee":              moneyField,
+	},
+}
+
+// required fields of each entity, the customer of the contacts and agreements is checked apart
+var requiredFields = map[Entity][]string{
+	Customers:  {"refId", "name"},
+	Contacts:   {"refId"},
+	Agreements: {"refId", "startDate"},
+}
+
+func hasCustomAttributes(entity Entity) bool {
+	return entity == Customers || entity == Contacts
+}
+
+// check returns an error if a field of the mapping is not a field of the entity
+func (m Mapping) check(entity Entity) error {
+	known, ok := fields[entity]
+	if !ok {
+		return fmt.Errorf("unknown entity %q", entity)
+	}
+	for field := range m {
+		if _, ok := known[field]; ok {
+			continue
+		}
+		if key, found := strings.CutPrefix(field, CustomAttributePrefix); found && key != "" && hasCustomAttributes(entity) {
+			continue
+		}
+		return fmt.Errorf("unknown field %q for %v", field, entity)
+	}
+	return nil
+}
+
+// defaultMapping maps the columns named like the fields of the entity
+func defaultMapping(entity Entity, columns []string) Mapping {
+	mapping := Mapping{}
+	for _, column := range columns {
+		if _, ok := fields[entity][column]; ok {
+			mapping[column] = column
+		} else if strings.HasPrefix(column, CustomAttributePrefix) && hasCustomAttributes(entity) {
+			mapping[column] = column
+		}
+	}
+	return mapping
+}
+
+// values converts the record to the JSON values of the entity, custom attributes are at the root like in the Skalin API
+func (m Mapping) values(entity Entity, record *records.Record, opts Options) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(m))
+	for field, column := range m {
+		raw := strings.TrimSpace(record.Fields[column])
+		if raw == "" {
+			continue
+		}
+		if key, found := strings.CutPrefix(field, CustomAttributePrefix); found {
+			values[key] = raw
+			continue
+		}
+		value, err := parseField(fields[entity][field], raw, opts)
+		if err != nil {
+			return nil, fmt.Errorf("field %v (column %v): %w", field, column, err)
+		}
+		values[field] = value
+	}
+	for _, field := range requiredFields[entity] {
+		if _, ok := values[field]; !ok {
+			return nil, fmt.Errorf("field %v is required", field)
+		}
+	}
+	return values, nil
+}
+
+func parseField(t fieldType, raw string, opts Options) (interface{}, error) {
+	switch t {
+	case intField:
+		return strconv.Atoi(raw)
+	case boolField:
+		return strconv.ParseBool(raw)
+	case tagsField:
+		tags := make([]string, 0)
+		for _, tag := range strings.Split(raw, opts.TagsSeparator) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		return tags, nil
+	case dateField:
+		d, err := time.Parse(opts.DateLayout, raw)
+		if err != nil {
+			return nil, err
+		}
+		return d.Format(time.DateOnly), nil
+	case timeField:
+		t, err := time.Parse(opts.TimeLayout, raw)
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	case moneyField:
+		return skalinsdk.ParseMoney(raw, opts.Currency)
+	case periodField:
+		return checkEnum("period", raw, skalinsdk.Period(raw).IsValid(), opts)
+	case agreementTypeField:
+		return checkEnum("agreement type", raw, skalinsdk.AgreementType(raw).IsValid(), opts)
+	case stageField:
+		return checkEnum("customer stage", raw, isCustomerStage(raw, opts.CustomerStages), opts)
+	}
+	return raw, nil
+}
+
+// checkEnum rejects an unknown enum value in strict mode, as the client does before saving the entity
+func checkEnum(name, raw string, valid bool, opts Options) (interface{}, error) {
+	if !valid && opts.EnumMode != skalinsdk.EnumLenient {
+		return nil, &skalinsdk.EnumError{Type: name, Value: raw}
+	}
+	return raw, nil
+}
+
+// isCustomerStage returns true if the stage is in the stages, or if there is no stage
+func isCustomerStage(raw string, stages []skalinsdk.CustomerStage) bool {
+	for _, stage := range stages {
+		if string(stage) == raw {
+			return true
+		}
+	}
+	return len(stages) == 0
+}
@@ -0,0 +1,224 @@
+// Command mockgen generates the testify mocks of the interfaces declared in skalin.go.
+// It is run by `go generate` from the root of the module:
+//
+//	go generate ./...
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	sourceFile = "skalin.go"
+	targetFile = "mock_skalin.go"
+)
+
+// interfaces to mock, with the name of their mock
+var mocks = []struct {
+	Interface string
+	Mock      string
+}{
+	{"Skalin", "MockSkalin"},
+	{"SkalinTracking", "MockSkalinTracking"},
+}
+
+func main() {
+	src, err := os.ReadFile(sourceFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	generated, err := generate(src)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(targetFile, generated, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type method struct {
+	name    string
+	params  []string // types of the params
+	results []string // types of the results
+}
+
+func generate(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, sourceFile, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	imports := make(map[string]string) // package name => import path
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+
+	usedImports := map[string]bool{"github.com/stretchr/testify/mock": true}
+	var body bytes.Buffer
+	for _, m := range mocks {
+		iface := findInterface(file, m.Interface)
+		if iface == nil {
+			return nil, fmt.Errorf("interface %v not found in %v", m.Interface, sourceFile)
+		}
+		methods := make([]method, 0)
+		for _, field := range iface.Methods.List {
+			funcType, ok := field.Type.(*ast.FuncType)
+			if !ok {
+				return nil, fmt.Errorf("embedded interfaces are not supported in %v", m.Interface)
+			}
+			for _, name := range field.Names {
+				methods = append(methods, method{
+					name:    name.Name,
+					params:  fieldTypes(fset, funcType.Params, imports, usedImports),
+					results: fieldTypes(fset, funcType.Results, imports, usedImports),
+				})
+			}
+		}
+		writeMock(&body, m.Interface, m.Mock, methods)
+	}
+
+	paths := make([]string, 0, len(usedImports))
+	for path := range usedImports {
+		paths = append(paths, path)
+	}
+	// standard library first, like goimports
+	sort.Slice(paths, func(i, j int) bool {
+		iStd, jStd := isStandardImport(paths[i]), isStandardImport(paths[j])
+		if iStd != jStd {
+			return iStd
+		}
+		return paths[i] < paths[j]
+	})
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by internal/mockgen from %v. DO NOT EDIT.\n\n", sourceFile)
+	fmt.Fprintf(&out, "package %v\n\nimport (\n", file.Name.Name)
+	for i, path := range paths {
+		if i > 0 && isStandardImport(paths[i-1]) && !isStandardImport(path) {
+			fmt.Fprintf(&out, "\n")
+		}
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	fmt.Fprintf(&out, ")\n\n")
+	fmt.Fprintf(&out, "// MockTestingT is the subset of testing.TB used by the mocks\n")
+	fmt.Fprintf(&out, "type MockTestingT interface {\n\tmock.TestingT\n\tCleanup(func())\n}\n")
+	out.Write(body.Bytes())
+	return format.Source(out.Bytes())
+}
+
+func isStandardImport(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
+
+func findInterface(file *ast.File, name string) *ast.InterfaceType {
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok && typeSpec.Name.Name == name {
+				return iface
+			}
+		}
+	}
+	return nil
+}
+
+func fieldTypes(fset *token.FileSet, fields *ast.FieldList, imports map[string]string, usedImports map[string]bool) []string {
+	types := make([]string, 0)
+	if fields == nil {
+		return types
+	}
+	for _, field := range fields.List {
+		ast.Inspect(field.Type, func(n ast.Node) bool {
+			if selector, ok := n.(*ast.SelectorExpr); ok {
+				if pkg, ok := selector.X.(*ast.Ident); ok {
+					usedImports[imports[pkg.Name]] = true
+				}
+			}
+			return true
+		})
+		var b bytes.Buffer
+		_ = printer.Fprint(&b, fset, field.Type)
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			types = append(types, b.String())
+		}
+	}
+	return types
+}
+
+func writeMock(w *bytes.Buffer, iface, mockName string, methods []method) {
+	fmt.Fprintf(w, "\n// %v is a mock of the %v interface\n", mockName, iface)
+	fmt.Fprintf(w, "type %v struct {\n\tmock.Mock\n}\n\n", mockName)
+	fmt.Fprintf(w, "var _ %v = (*%v)(nil)\n\n", iface, mockName)
+	fmt.Fprintf(w, "// New%v creates a mock which asserts that all its expectations were met at the end of the test\n", mockName)
+	fmt.Fprintf(w, "func New%v(t MockTestingT) *%v {\n\tm := new(%v)\n\tm.Test(t)\n\tt.Cleanup(func() { m.AssertExpectations(t) })\n\treturn m\n}\n", mockName, mockName, mockName)
+
+	for _, m := range methods {
+		callName := mockName + m.name + "Call"
+		params := make([]string, len(m.params))
+		args := make([]string, len(m.params))
+		expectParams := make([]string, len(m.params))
+		for i, paramType := range m.params {
+			args[i] = fmt.Sprintf("arg%d", i)
+			params[i] = fmt.Sprintf("arg%d %v", i, paramType)
+			expectParams[i] = fmt.Sprintf("arg%d interface{}", i)
+		}
+		results := strings.Join(m.results, ", ")
+		if len(m.results) > 1 {
+			results = "(" + results + ")"
+		}
+
+		fmt.Fprintf(w, "\nfunc (m *%v) %v(%v) %v {\n", mockName, m.name, strings.Join(params, ", "), results)
+		if len(m.results) == 0 {
+			fmt.Fprintf(w, "\tm.Called(%v)\n}\n", strings.Join(args, ", "))
+		} else {
+			fmt.Fprintf(w, "\targs := m.Called(%v)\n", strings.Join(args, ", "))
+			returned := make([]string, len(m.results))
+			for i, resultType := range m.results {
+				if resultType == "error" {
+					returned[i] = fmt.Sprintf("args.Error(%d)", i)
+					continue
+				}
+				returned[i] = fmt.Sprintf("r%d", i)
+				fmt.Fprintf(w, "\tvar r%d %v\n\tif v := args.Get(%d); v != nil {\n\t\tr%d = v.(%v)\n\t}\n", i, resultType, i, i, resultType)
+			}
+			fmt.Fprintf(w, "\treturn %v\n}\n", strings.Join(returned, ", "))
+		}
+
+		fmt.Fprintf(w, "\n// %v is an expectation on %v.%v\n", callName, mockName, m.name)
+		fmt.Fprintf(w, "type %v struct {\n\t*mock.Call\n}\n", callName)
+		fmt.Fprintf(w, "\n// Expect%v expects a call of %v with the given arguments (values or mock.Anything)\n", m.name, m.name)
+		onArgs := append([]string{strconv.Quote(m.name)}, args...)
+		fmt.Fprintf(w, "func (m *%v) Expect%v(%v) *%v {\n\treturn &%v{m.On(%v)}\n}\n", mockName, m.name, strings.Join(expectParams, ", "), callName, callName, strings.Join(onArgs, ", "))
+		returnParams := make([]string, len(m.results))
+		returnArgs := make([]string, len(m.results))
+		for i, resultType := range m.results {
+			returnParams[i] = fmt.Sprintf("r%d %v", i, resultType)
+			returnArgs[i] = fmt.Sprintf("r%d", i)
+		}
+		fmt.Fprintf(w, "\n// Return sets the values returned by %v\n", m.name)
+		fmt.Fprintf(w, "func (c *%v) Return(%v) *%v {\n\tc.Call.Return(%v)\n\treturn c\n}\n", callName, strings.Join(returnParams, ", "), callName, strings.Join(returnArgs, ", "))
+	}
+}
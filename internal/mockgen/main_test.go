@@ -0,0 +1,26 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the generated mocks must be up to date with the interfaces, run `go generate ./...` otherwise
+func TestGeneratedMocksAreUpToDate(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("..", "..", sourceFile))
+	if !assert.NoError(t, err) {
+		return
+	}
+	generated, err := generate(src)
+	if !assert.NoError(t, err) {
+		return
+	}
+	current, err := os.ReadFile(filepath.Join("..", "..", targetFile))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, string(generated), string(current))
+}
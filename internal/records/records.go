@@ -0,0 +1,214 @@
+// Package records reads and writes flat records in CSV or JSON Lines files.
+// It is shared by the subsystems which load data from files or dump data to files (backfill, import, export).
+package records
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+// FormatFromPath guesses the format of a file from its extension
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	}
+	return "", fmt.Errorf("unable to guess the format of %v, expected a .csv or .jsonl file", path)
+}
+
+// Record is a row of the file. Line is the 1-based number of the record in the file
+// (the CSV header and the blank lines are not counted), so the records are numbered without gap.
+// FileLine is the line of the file where the record starts, for the messages
+type Record struct {
+	Line     int
+	FileLine int
+	Fields   map[string]string
+}
+
+type Reader interface {
+	// Read returns io.EOF when there is no more record
+	Read() (*Record, error)
+}
+
+func NewReader(r io.Reader, format Format) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		csvReader := csv.NewReader(r)
+		header, err := csvReader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("error to read csv header: %w", err)
+		}
+		return &csvRecordReader{reader: csvReader, header: header}, nil
+	case FormatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		return &jsonlRecordReader{scanner: scanner}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+type csvRecordReader struct {
+	reader *csv.Reader
+	header []string
+	line   int
+}
+
+func (r *csvRecordReader) Read() (*Record, error) {
+	row, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	r.line++
+	fileLine, _ := r.reader.FieldPos(0)
+	fields := make(map[string]string, len(r.header))
+	for i, column := range r.header {
+		if i < len(row) {
+			fields[column] = row[i]
+		}
+	}
+	return &Record{Line: r.line, FileLine: fileLine, Fields: fields}, nil
+}
+
+type jsonlRecordReader struct {
+	scanner *bufio.Scanner
+	line    int // line of the file, for the errors
+	record  int
+}
+
+func (r *jsonlRecordReader) Read() (*Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		r.record++
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		var values map[string]interface{}
+		if err := decoder.Decode(&values); err != nil {
+			return nil, fmt.Errorf("line %v: %w", r.line, err)
+		}
+		fields := make(map[string]string, len(values))
+		for key, value := range values {
+			field, err := fieldToString(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %v: field %v: %w", r.line, key, err)
+			}
+			fields[key] = field
+		}
+		return &Record{Line: r.record, FileLine: r.line, Fields: fields}, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// nested values are kept as raw JSON so the caller can decode them if needed
+func fieldToString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return fmt.Sprintf("%v", v), nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Writer writes flat records. The CSV header is written by NewWriter, so a file without record still has it
+type Writer interface {
+	Write(fields map[string]string) error
+	// Flush writes the buffered records to the underlying writer
+	Flush() error
+}
+
+// NewWriter returns a writer of the given columns. With the JSON Lines format and no column,
+// all the fields of each record are written
+func NewWriter(w io.Writer, format Format, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("columns are required by the csv format")
+		}
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(columns); err != nil {
+			return nil, fmt.Errorf("error to write csv header: %w", err)
+		}
+		return &csvRecordWriter{writer: csvWriter, columns: columns}, nil
+	case FormatJSONL:
+		buffered := bufio.NewWriter(w)
+		return &jsonlRecordWriter{writer: buffered, encoder: json.NewEncoder(buffered), columns: columns}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+type csvRecordWriter struct {
+	writer  *csv.Writer
+	columns []string
+}
+
+func (w *csvRecordWriter) Write(fields map[string]string) error {
+	row := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		row[i] = fields[column]
+	}
+	return w.writer.Write(row)
+}
+
+func (w *csvRecordWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type jsonlRecordWriter struct {
+	writer  *bufio.Writer
+	encoder *json.Encoder
+	columns []string
+}
+
+// empty fields are omitted, like the empty cells of a CSV file are read as missing values
+func (w *jsonlRecordWriter) Write(fields map[string]string) error {
+	values := make(map[string]string, len(fields))
+	if len(w.columns) == 0 {
+		for key, value := range fields {
+			if value != "" {
+				values[key] = value
+			}
+		}
+	} else {
+		for _, column := range w.columns {
+			if value := fields[column]; value != "" {
+				values[column] = value
+			}
+		}
+	}
+	return w.encoder.Encode(values)
+}
+
+func (w *jsonlRecordWriter) Flush() error {
+	return w.writer.Flush()
+}
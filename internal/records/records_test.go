@@ -0,0 +1,110 @@
+package records
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(t *testing.T, reader Reader) []*Record {
+	result := make([]*Record, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return result
+		}
+		if !assert.NoError(t, err) {
+			return result
+		}
+		result = append(result, record)
+	}
+}
+
+func TestReader(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		reader, err := NewReader(strings.NewReader("a,b\n1,2\n3,\"4,5\"\n"), FormatCSV)
+		if !assert.NoError(t, err) {
+			return
+		}
+		result := readAll(t, reader)
+		if !assert.Len(t, result, 2) {
+			return
+		}
+		assert.Equal(t, 2, result[1].Line)
+		// the header is the first line of the file
+		assert.Equal(t, 3, result[1].FileLine)
+		assert.Equal(t, map[string]string{"a": "3", "b": "4,5"}, result[1].Fields)
+	})
+
+	t.Run("JSONL", func(t *testing.T) {
+		reader, err := NewReader(strings.NewReader(`{"a": "1", "b": 2.50, "c": true}`+"\n\n"+`{"a": {"nested": 1}}`+"\n"), FormatJSONL)
+		if !assert.NoError(t, err) {
+			return
+		}
+		result := readAll(t, reader)
+		if !assert.Len(t, result, 2) {
+			return
+		}
+		assert.Equal(t, map[string]string{"a": "1", "b": "2.50", "c": "true"}, result[0].Fields)
+		// the blank line is not counted
+		assert.Equal(t, 2, result[1].Line)
+		assert.Equal(t, 3, result[1].FileLine)
+		assert.Equal(t, `{"nested":1}`, result[1].Fields["a"])
+	})
+
+	t.Run("Format from path", func(t *testing.T) {
+		format, err := FormatFromPath("events.JSONL")
+		assert.NoError(t, err)
+		assert.Equal(t, FormatJSONL, format)
+		_, err = FormatFromPath("events.xlsx")
+		assert.Error(t, err)
+	})
+}
+
+func TestWriter(t *testing.T) {
+	fields := []map[string]string{{"a": "1", "b": "2,3", "c": "ignored"}, {"a": "4"}}
+	t.Run("CSV", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		writer, err := NewWriter(buf, FormatCSV, []string{"a", "b"})
+		if !assert.NoError(t, err) {
+			return
+		}
+		for _, f := range fields {
+			assert.NoError(t, writer.Write(f))
+		}
+		assert.NoError(t, writer.Flush())
+		assert.Equal(t, "a,b\n1,\"2,3\"\n4,\n", buf.String())
+
+		records := readAll(t, mustReader(t, buf, FormatCSV))
+		assert.Equal(t, map[string]string{"a": "4", "b": ""}, records[1].Fields)
+	})
+
+	t.Run("JSONL", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		writer, err := NewWriter(buf, FormatJSONL, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		for _, f := range fields {
+			assert.NoError(t, writer.Write(f))
+		}
+		assert.NoError(t, writer.Flush())
+		assert.Equal(t, `{"a":"1","b":"2,3","c":"ignored"}`+"\n"+`{"a":"4"}`+"\n", buf.String())
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		_, err := NewWriter(io.Discard, FormatCSV, nil)
+		assert.Error(t, err)
+		_, err = NewWriter(io.Discard, "xml", []string{"a"})
+		assert.Error(t, err)
+	})
+}
+
+func mustReader(t *testing.T, r io.Reader, format Format) Reader {
+	reader, err := NewReader(r, format)
+	assert.NoError(t, err)
+	return reader
+}
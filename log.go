@@ -51,6 +51,28 @@ func NewLogger() *CustomLog {
 		l,
 	}
 }
+
+// NewLogrusLogger adapts a logrus logger to the Logger interface
+func NewLogrusLogger(logger logrus.FieldLogger) Logger {
+	return &CustomLog{
+		logger,
+	}
+}
+
+func (logger *CustomLog) Log(level Level, msg string, fields Fields) {
+	entry := logger.AddSkalinApplicationField().WithFields(logrus.Fields(fields))
+	switch level {
+	case LevelDebug:
+		entry.Debug(msg)
+	case LevelInfo:
+		entry.Info(msg)
+	case LevelWarn:
+		entry.Warn(msg)
+	default:
+		entry.Error(msg)
+	}
+}
+
 func (logger *CustomLog) AddSkalinApplicationField() *logrus.Entry {
 	return logger.WithFields(logrus.Fields{
 		"application": "skalin",
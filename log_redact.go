@@ -0,0 +1,69 @@
+package skalinsdk
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+var (
+	// fields which are never logged
+	sensitiveFieldNames = []string{"token", "secret", "password", "authorization", "phone"}
+
+	// phone numbers can't be told apart from ids or timestamps in a free text,
+	// so they are only redacted from the phone fields
+	sensitiveJSONFieldPattern = regexp.MustCompile(`"(access_token|client_secret|token|password|email|phone)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	bearerPattern             = regexp.MustCompile(`Bearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	emailPattern              = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+type redactingLogger struct {
+	logger Logger
+}
+
+// NewRedactingLogger returns a logger which removes tokens, secrets, emails and phone numbers
+// from the messages and fields before logging them with logger.
+// It is used by default by the SDK, see WithLogRedaction to disable it
+func NewRedactingLogger(logger Logger) Logger {
+	if _, ok := logger.(*redactingLogger); ok {
+		return logger
+	}
+	return &redactingLogger{logger: logger}
+}
+
+func (l *redactingLogger) Log(level Level, msg string, fields Fields) {
+	redactedFields := make(Fields, len(fields))
+	for key, value := range fields {
+		redactedFields[key] = redactField(key, value)
+	}
+	l.logger.Log(level, Redact(msg), redactedFields)
+}
+
+func redactField(key string, value interface{}) interface{} {
+	lowerKey := strings.ToLower(key)
+	for _, name := range sensitiveFieldNames {
+		if strings.Contains(lowerKey, name) {
+			return redactedValue
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return Redact(v)
+	case []byte:
+		return Redact(string(v))
+	case error:
+		return Redact(v.Error())
+	case fmt.Stringer:
+		return Redact(v.String())
+	}
+	return value
+}
+
+// Redact removes tokens, secrets, emails and the phone numbers of the JSON fields from a string
+func Redact(s string) string {
+	s = sensitiveJSONFieldPattern.ReplaceAllString(s, `"$1"$2"`+redactedValue+`"`)
+	s = bearerPattern.ReplaceAllString(s, "Bearer "+redactedValue)
+	return emailPattern.ReplaceAllString(s, redactedValue)
+}
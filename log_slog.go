@@ -0,0 +1,47 @@
+//go:build go1.21
+
+package skalinsdk
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger adapts a log/slog logger to the Logger interface
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger.With("application", "skalin")}
+}
+
+func (l *slogLogger) Log(level Level, msg string, fields Fields) {
+	var slogLevel slog.Level
+	switch level {
+	case LevelDebug:
+		slogLevel = slog.LevelDebug
+	case LevelInfo:
+		slogLevel = slog.LevelInfo
+	case LevelWarn:
+		slogLevel = slog.LevelWarn
+	default:
+		slogLevel = slog.LevelError
+	}
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, slogLevel) {
+		return
+	}
+	// sort the fields to always log them in the same order
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+	l.logger.LogAttrs(ctx, slogLevel, msg, attrs...)
+}
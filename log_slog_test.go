@@ -0,0 +1,28 @@
+//go:build go1.21
+
+package skalinsdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	logger.Log(LevelDebug, "debug", nil)
+	logger.Log(LevelError, "error", Fields{"statusCode": 500})
+
+	var entry map[string]interface{}
+	if !assert.NoError(t, json.Unmarshal(buffer.Bytes(), &entry)) {
+		return
+	}
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, "error", entry["msg"])
+	assert.Equal(t, "skalin", entry["application"])
+	assert.Equal(t, float64(500), entry["statusCode"])
+}
@@ -0,0 +1,152 @@
+package skalinsdk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type logEntry struct {
+	level  Level
+	msg    string
+	fields Fields
+}
+
+type captureLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *captureLogger) Log(level Level, msg string, fields Fields) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level, msg, fields})
+}
+
+func (l *captureLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return fmt.Sprintf("%v", l.entries)
+}
+
+func TestRedact(t *testing.T) {
+	testCases := map[string]string{
+		"contact contact+test@karnott.fr not found":                             "contact [REDACTED] not found",
+		`{"email":"contact@karnott.fr","phone":"01-23-45-67-89","refId":"2"}`:   `{"email":"[REDACTED]","phone":"[REDACTED]","refId":"2"}`,
+		`{"access_token": "eyJhbGciOi.eyJzdWIi.SflKxwRJ", "expires_in": 86400}`: `{"access_token": "[REDACTED]", "expires_in": 86400}`,
+		"Authorization: Bearer eyJhbGciOi.eyJzdWIi":                             "Authorization: Bearer [REDACTED]",
+		// the numbers of a free text are kept, they can be ids or timestamps
+		"visit 0123456789 at 1679801400 for order +33123456789":       "visit 0123456789 at 1679801400 for order +33123456789",
+		"renewal on 2023-03-26 for customer 6475c1f3a5b1c2d3e4f50001": "renewal on 2023-03-26 for customer 6475c1f3a5b1c2d3e4f50001",
+	}
+	for input, expected := range testCases {
+		assert.Equal(t, expected, Redact(input))
+	}
+}
+
+func TestRedactingLogger(t *testing.T) {
+	logger := &captureLogger{}
+	NewRedactingLogger(logger).Log(LevelError, "error for contact@karnott.fr", Fields{
+		"clientSecret": "secret",
+		"accessToken":  "token",
+		"phone":        "+33 1 23 45 67 89",
+		"error":        errors.New(`{"phone":"0123456789"} is invalid`),
+		"statusCode":   400,
+	})
+	if !assert.Len(t, logger.entries, 1) {
+		return
+	}
+	entry := logger.entries[0]
+	assert.Equal(t, LevelError, entry.level)
+	assert.Equal(t, "error for [REDACTED]", entry.msg)
+	assert.Equal(t, Fields{
+		"clientSecret": "[REDACTED]",
+		"accessToken":  "[REDACTED]",
+		"phone":        "[REDACTED]",
+		"error":        `{"phone":"[REDACTED]"} is invalid`,
+		"statusCode":   400,
+	}, entry.fields)
+}
+
+func TestLogrusLogger(t *testing.T) {
+	logrusLogger, hook := test.NewNullLogger()
+	logrusLogger.SetLevel(logrus.InfoLevel)
+	logger := NewLogrusLogger(logrusLogger)
+	logger.Log(LevelDebug, "debug", nil)
+	logger.Log(LevelWarn, "warn", Fields{"key": "value"})
+	if !assert.Len(t, hook.AllEntries(), 1) {
+		return
+	}
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "warn", entry.Message)
+	assert.Equal(t, "value", entry.Data["key"])
+	assert.Equal(t, "skalin", entry.Data["application"])
+}
+
+func TestClientLogs(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+
+	t.Run("Redacted by default", func(t *testing.T) {
+		logger := &captureLogger{}
+		skalinApi, err := New("clientId", "clientApiId", "clientApiSecret", WithHTTPClient(server.Client()), WithLogger(logger))
+		if !assert.NoError(t, err) {
+			return
+		}
+		unknownCustomer := "unknown"
+		_, err = skalinApi.SaveContact(Contact{RefId: "1", Email: "contact@karnott.fr", Customer: &unknownCustomer})
+		assert.Error(t, err)
+		logs := logger.String()
+		assert.Contains(t, logs, "error to read response from skalin API")
+		assert.NotContains(t, logs, "contact@karnott.fr")
+		assert.NotContains(t, logs, "clientApiSecret")
+		for _, entry := range logger.entries {
+			// the requests are only logged at debug level
+			if entry.msg == "call skalin API" {
+				assert.Equal(t, LevelDebug, entry.level)
+			}
+		}
+	})
+
+	t.Run("Without redaction", func(t *testing.T) {
+		logger := &captureLogger{}
+		skalinApi, err := New("clientId", "clientApiId", "clientApiSecret", WithHTTPClient(server.Client()), WithLogger(logger), WithLogRedaction(false))
+		if !assert.NoError(t, err) {
+			return
+		}
+		unknownCustomer := "unknown"
+		_, err = skalinApi.SaveContact(Contact{RefId: "1", Email: "contact@karnott.fr", Customer: &unknownCustomer})
+		assert.Error(t, err)
+		assert.True(t, strings.Contains(logger.String(), "contact@karnott.fr"))
+	})
+
+	t.Run("Nop logger", func(t *testing.T) {
+		_, err := New("clientId", "clientApiId", "clientApiSecret", WithHTTPClient(server.Client()), WithLogger(NewNopLogger()))
+		assert.NoError(t, err)
+	})
+}
+
+func TestGetLogger(t *testing.T) {
+	skalinApi := new(SkalinAPI)
+	WithLogger(&captureLogger{})(skalinApi)
+	skalinApi.setupLogger()
+	// the logger is only wrapped once, and GetLogger keeps returning a logrus logger
+	assert.Same(t, skalinApi.log(), skalinApi.log())
+	assert.Same(t, Log, skalinApi.GetLogger())
+
+	logrusLogger, hook := test.NewNullLogger()
+	skalinApi.SetLogger(logrusLogger)
+	assert.Equal(t, logrus.FieldLogger(logrusLogger), skalinApi.GetLogger().FieldLogger)
+	skalinApi.log().Log(LevelWarn, "error for contact@karnott.fr", nil)
+	if assert.Len(t, hook.AllEntries(), 1) {
+		assert.Equal(t, "error for [REDACTED]", hook.LastEntry().Message)
+	}
+}
@@ -0,0 +1,43 @@
+package skalinsdk
+
+import "fmt"
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+type Fields map[string]interface{}
+
+// Logger is the interface used by the SDK to log.
+// Adapters are provided for logrus (NewLogrusLogger), log/slog (NewSlogLogger) and to disable logs (NewNopLogger)
+type Logger interface {
+	Log(level Level, msg string, fields Fields)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Log(Level, string, Fields) {}
+
+// NewNopLogger returns a logger which discards all the logs
+func NewNopLogger() Logger {
+	return nopLogger{}
+}
@@ -0,0 +1,192 @@
+package skalinsdk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Request is a call to Skalin, as seen by the middlewares.
+// The middlewares can change it before calling the next handler
+type Request struct {
+	Context            context.Context
+	Method             string
+	URL                string // URL without the query params
+	Path               string // path of the entity in the API, like `/contacts/123` (empty for the auth and hit URLs)
+	Route              string // path with the ids replaced, like `/contacts/{id}`
+	Header             http.Header
+	Query              url.Values
+	Body               []byte
+	ExpectedStatusCode int
+	ClientID           *string
+	Token              *string
+	Retries            int           // number of retries done by RetryMiddleware
+	RateLimitWait      time.Duration // time waited by RetryMiddleware because of the rate limits
+}
+
+// Response is the response of Skalin, with its body already read
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	HTTP       *http.Response // nil if the response was built by a middleware
+}
+
+// Handler sends a request to Skalin
+type Handler func(req *Request) (*Response, error)
+
+// Middleware wraps the next handler of the chain.
+// It can short-circuit the chain by returning a response or an error without calling next
+type Middleware func(next Handler) Handler
+
+// AuthorizationMiddleware adds the Authorization header with the token of the client
+func AuthorizationMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (*Response, error) {
+			if req.Token != nil {
+				req.Header.Set("Authorization", "Bearer "+*req.Token)
+			}
+			return next(req)
+		}
+	}
+}
+
+// ClientIDMiddleware adds the clientId query param
+func ClientIDMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (*Response, error) {
+			if req.ClientID != nil {
+				req.Query.Set("clientId", *req.ClientID)
+			}
+			return next(req)
+		}
+	}
+}
+
+// AcceptLanguageMiddleware sets the Accept-Language header
+func AcceptLanguageMiddleware(language string) Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (*Response, error) {
+			req.Header.Set("Accept-Language", language)
+			return next(req)
+		}
+	}
+}
+
+// DefaultMiddlewares returns the middlewares used when the chain is not replaced with WithMiddlewareChain
+func DefaultMiddlewares() []Middleware {
+	return []Middleware{
+		AuthorizationMiddleware(),
+		ClientIDMiddleware(),
+		AcceptLanguageMiddleware("fr"),
+	}
+}
+
+// RouteTemplate replaces the ids of an API path by `{id}`, like `/customers/{id}/contacts` for `/customers/123/contacts`
+func RouteTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		switch segment {
+		case "", "customers", "contacts", "agreements", "tags":
+		default:
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func newRequest(method, queryUrl, contentType string, extraHeaders map[string][]string, body []byte, queryParams *url.Values, expectedStatusCode int) *Request {
+	req := &Request{
+		Context:            context.Background(),
+		Method:             method,
+		URL:                queryUrl,
+		Header:             http.Header{},
+		Query:              url.Values{},
+		Body:               body,
+		ExpectedStatusCode: expectedStatusCode,
+	}
+	for key, values := range extraHeaders {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if queryParams != nil {
+		for key, values := range *queryParams {
+			req.Query[key] = append([]string(nil), values...)
+		}
+	}
+	if strings.HasPrefix(queryUrl, GetAPIUrl()) {
+		req.Path = strings.TrimPrefix(queryUrl, GetAPIUrl())
+		req.Route = RouteTemplate(req.Path)
+	} else if u, err := url.Parse(queryUrl); err == nil {
+		req.Route = u.Path
+	}
+	return req
+}
+
+// handler returns the middleware chain ending with the HTTP call
+func (a SkalinAPI) handler() Handler {
+	middlewares := a.middlewares
+	if !a.replaceMiddlewares {
+		middlewares = append(DefaultMiddlewares(), a.middlewares...)
+	}
+	h := a.doRequest
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+func (a SkalinAPI) doRequest(req *Request) (*Response, error) {
+	var bodyReader io.Reader
+	if req.Body != nil {
+		bodyReader = bytes.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequestWithContext(req.Context, req.Method, req.URL, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header = req.Header.Clone()
+	httpReq.URL.RawQuery = req.Query.Encode()
+	client := http.DefaultClient
+	if a.httpClient != nil {
+		client = a.httpClient
+	}
+	res, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return &Response{StatusCode: res.StatusCode, Header: res.Header, Body: body, HTTP: res}, nil
+}
+
+// httpResponse returns the HTTP response of Skalin, or builds one for the responses of the middlewares
+func (r *Response) httpResponse() *http.Response {
+	if r.HTTP != nil {
+		return r.HTTP
+	}
+	header := r.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+	}
+}
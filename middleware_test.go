@@ -0,0 +1,127 @@
+package skalinsdk
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureMiddleware keeps the requests and responses seen in the chain
+type captureMiddleware struct {
+	requests  []Request
+	responses []*Response
+}
+
+func (c *captureMiddleware) middleware(next Handler) Handler {
+	return func(req *Request) (*Response, error) {
+		res, err := next(req)
+		c.requests = append(c.requests, *req)
+		c.responses = append(c.responses, res)
+		return res, err
+	}
+}
+
+func TestMiddlewares(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+
+	t.Run("OK", func(t *testing.T) {
+		capture := &captureMiddleware{}
+		skalinApi, err := New("clientId", "clientApiId", "clientApiSecret", WithHTTPClient(server.Client()), WithMiddlewares(capture.middleware))
+		if !assert.NoError(t, err) {
+			return
+		}
+		contact, err := skalinApi.SaveContact(Contact{RefId: "1", Email: "contact@karnott.fr"})
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = skalinApi.UpdateContact(Contact{Id: contact.Id, Email: "contact2@karnott.fr"})
+		assert.NoError(t, err)
+
+		if !assert.Len(t, capture.requests, 3) {
+			return
+		}
+		auth := capture.requests[0]
+		assert.Equal(t, http.MethodPost, auth.Method)
+		assert.Equal(t, "", auth.Path)
+		assert.Equal(t, "/oauth/token", auth.Route)
+
+		save := capture.requests[1]
+		assert.Equal(t, http.MethodPost, save.Method)
+		assert.Equal(t, "/contacts", save.Path)
+		assert.Equal(t, "/contacts", save.Route)
+		assert.Contains(t, string(save.Body), "contact@karnott.fr")
+		assert.Equal(t, "clientId", save.Query.Get("clientId"))
+		assert.Contains(t, save.Header.Get("Authorization"), "Bearer ")
+		assert.Equal(t, "fr", save.Header.Get("Accept-Language"))
+		assert.Equal(t, http.StatusOK, capture.responses[1].StatusCode)
+		assert.Contains(t, string(capture.responses[1].Body), contact.Id)
+
+		update := capture.requests[2]
+		assert.Equal(t, http.MethodPatch, update.Method)
+		assert.Equal(t, "/contacts/"+contact.Id, update.Path)
+		assert.Equal(t, "/contacts/{id}", update.Route)
+	})
+
+	t.Run("Short-circuit", func(t *testing.T) {
+		calls := 0
+		skalinApi, err := New("clientId", "clientApiId", "clientApiSecret", WithHTTPClient(server.Client()), WithMiddlewares(func(next Handler) Handler {
+			return func(req *Request) (*Response, error) {
+				if req.Route != "/tags/{id}" {
+					return next(req)
+				}
+				calls++
+				return &Response{StatusCode: http.StatusOK, Body: []byte(`{"status":"success","data":{"id":"cached","name":"Cached"}}`)}, nil
+			}
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+		tag, err := skalinApi.GetTagByID("unknown")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		if assert.NotNil(t, tag) {
+			assert.Equal(t, "cached", tag.Id)
+		}
+	})
+
+	t.Run("Replaced chain", func(t *testing.T) {
+		capture := &captureMiddleware{}
+		skalinApi, err := New("clientId", "clientApiId", "clientApiSecret", WithHTTPClient(server.Client()), WithMiddlewareChain(
+			AcceptLanguageMiddleware("en"),
+			ClientIDMiddleware(),
+			AuthorizationMiddleware(),
+			capture.middleware,
+		))
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = skalinApi.GetTags(nil)
+		assert.NoError(t, err)
+		if assert.Len(t, capture.requests, 2) {
+			assert.Equal(t, "en", capture.requests[1].Header.Get("Accept-Language"))
+		}
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		skalinApi, err := New("clientId", "clientApiId", "clientApiSecret", WithHTTPClient(server.Client()), WithMiddlewareChain(AuthorizationMiddleware()))
+		if !assert.NoError(t, err) {
+			return
+		}
+		// without the clientId query param, the call is rejected by Skalin
+		_, err = skalinApi.GetTags(nil)
+		var httpErr *HTTPError
+		if assert.ErrorAs(t, err, &httpErr) {
+			assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+			assert.EqualError(t, err, "clientId is required")
+		}
+	})
+}
+
+func TestRouteTemplate(t *testing.T) {
+	assert.Equal(t, "/contacts", RouteTemplate("/contacts"))
+	assert.Equal(t, "/contacts/{id}", RouteTemplate("/contacts/123"))
+	assert.Equal(t, "/customers/{id}/agreements", RouteTemplate("/customers/123/agreements"))
+}
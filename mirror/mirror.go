@@ -0,0 +1,265 @@
+// Package mirror keeps a local copy of the Skalin entities in a bbolt file, to query them without paging through the API.
+//
+// The customers and contacts are refreshed incrementally: they are listed by descending lastActivityTs
+// until the high-water mark saved by the previous sync is reached. The agreements and tags have no activity timestamp,
+// they are fully refreshed by each sync. An incremental sync never sees the deleted entities, FullResync removes them.
+package mirror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	bolt "go.etcd.io/bbolt"
+)
+
+type Entity string
+
+const (
+	Customers  Entity = "customers"
+	Contacts   Entity = "contacts"
+	Agreements Entity = "agreements"
+	Tags       Entity = "tags"
+)
+
+// Entities are all the entities mirrored
+var Entities = []Entity{Customers, Contacts, Agreements, Tags}
+
+// bucket of the sync states, by entity
+var stateBucket = []byte("_state")
+
+const sortByActivity = "-lastActivityTs"
+
+// errStop stops the walk of the pages once the high-water mark is reached
+var errStop = errors.New("high-water mark reached")
+
+// State is the progress of the sync of an entity
+type State struct {
+	// HighWaterMark is the greatest lastActivityTs mirrored, only for the customers and contacts
+	HighWaterMark *time.Time `json:"highWaterMark,omitempty"`
+	LastSync      time.Time  `json:"lastSync"`
+	LastFullSync  time.Time  `json:"lastFullSync"`
+}
+
+// SyncResult is the result of the sync of an entity
+type SyncResult struct {
+	Full     bool // the entity was fully refreshed
+	Upserted int
+	Deleted  int
+}
+
+type Mirror struct {
+	db     *bolt.DB
+	client skalinsdk.Skalin
+	now    func() time.Time
+}
+
+// Open opens (or creates) the mirror file at path. The entities are read from Skalin with client
+func Open(path string, client skalinsdk.Skalin) (*Mirror, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error to open mirror %v: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range append([][]byte{stateBucket}, entityBuckets()...) {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error to create buckets: %w", err)
+	}
+	return &Mirror{db: db, client: client, now: time.Now}, nil
+}
+
+func (m *Mirror) Close() error {
+	return m.db.Close()
+}
+
+func entityBuckets() [][]byte {
+	buckets := make([][]byte, len(Entities))
+	for i, entity := range Entities {
+		buckets[i] = []byte(entity)
+	}
+	return buckets
+}
+
+// State returns the sync state of an entity, the zero state if it was never synced
+func (m *Mirror) State(entity Entity) (State, error) {
+	var state State
+	err := m.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(stateBucket).Get([]byte(entity))
+		if b == nil {
+			return nil
+		}
+		return json.Unmarshal(b, &state)
+	})
+	return state, err
+}
+
+func (m *Mirror) saveState(entity Entity, state State) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return m.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(stateBucket).Put([]byte(entity), b)
+	})
+}
+
+// Sync refreshes all the entities: incrementally for the customers and contacts already synced, fully otherwise.
+// It stops at the first entity which fails, the entities synced before keep their new state
+func (m *Mirror) Sync() (map[Entity]*SyncResult, error) {
+	return m.sync(false)
+}
+
+// FullResync reloads all the entities and removes the entities deleted in Skalin
+func (m *Mirror) FullResync() (map[Entity]*SyncResult, error) {
+	return m.sync(true)
+}
+
+func (m *Mirror) sync(full bool) (map[Entity]*SyncResult, error) {
+	results := make(map[Entity]*SyncResult, len(Entities))
+	for _, entity := range Entities {
+		result, err := m.SyncEntity(entity, full)
+		if err != nil {
+			return results, err
+		}
+		results[entity] = result
+	}
+	return results, nil
+}
+
+// SyncEntity refreshes an entity, fully if full is true or if the entity has no high-water mark
+func (m *Mirror) SyncEntity(entity Entity, full bool) (*SyncResult, error) {
+	state, err := m.State(entity)
+	if err != nil {
+		return nil, err
+	}
+	incremental := !full && state.HighWaterMark != nil && (entity == Customers || entity == Contacts)
+	s := &entitySync{mirror: m, entity: entity, seen: make(map[string]bool), result: &SyncResult{Full: !incremental}}
+	if incremental {
+		s.mark = *state.HighWaterMark
+	}
+	startedAt := m.now()
+
+	params := &skalinsdk.GetParams{}
+	if entity == Customers || entity == Contacts {
+		sort := sortByActivity
+		params.Sort = &sort
+	}
+	switch entity {
+	case Customers:
+		err = m.client.WalkCustomers(params, func(customers []skalinsdk.Customer) error {
+			return writePage(s, customers, func(c skalinsdk.Customer) (string, *time.Time) { return c.Id, c.LastActivityTs })
+		})
+	case Contacts:
+		err = m.client.WalkContacts(params, func(contacts []skalinsdk.Contact) error {
+			return writePage(s, contacts, func(c skalinsdk.Contact) (string, *time.Time) { return c.Id, c.LastActivityTs })
+		})
+	case Agreements:
+		err = m.client.WalkAgreements(params, func(agreements []skalinsdk.Agreement) error {
+			return writePage(s, agreements, func(a skalinsdk.Agreement) (string, *time.Time) { return a.Id, nil })
+		})
+	case Tags:
+		err = m.client.WalkTags(params, func(tags []skalinsdk.Tag) error {
+			return writePage(s, tags, func(t skalinsdk.Tag) (string, *time.Time) { return t.Id, nil })
+		})
+	default:
+		return nil, fmt.Errorf("unknown entity %q", entity)
+	}
+	if err != nil && !errors.Is(err, errStop) {
+		return s.result, fmt.Errorf("error to sync %v: %w", entity, err)
+	}
+
+	if !incremental {
+		if err := s.deleteUnseen(); err != nil {
+			return s.result, err
+		}
+		state.LastFullSync = startedAt
+	}
+	if s.maxTs != nil && (state.HighWaterMark == nil || s.maxTs.After(*state.HighWaterMark)) {
+		state.HighWaterMark = s.maxTs
+	}
+	state.LastSync = startedAt
+	if err := m.saveState(entity, state); err != nil {
+		return s.result, fmt.Errorf("error to save %v state: %w", entity, err)
+	}
+	return s.result, nil
+}
+
+// entitySync is the progress of the sync of an entity
+type entitySync struct {
+	mirror *Mirror
+	entity Entity
+	mark   time.Time       // zero for a full sync
+	seen   map[string]bool // ids listed, to remove the others after a full sync
+	maxTs  *time.Time
+	result *SyncResult
+}
+
+// writePage saves the entities of a page in a single transaction.
+// In an incremental sync, it returns errStop after the first entity older than the high-water mark:
+// the entities with the same lastActivityTs as the mark are saved again, as they may have been partially mirrored
+func writePage[T any](s *entitySync, entities []T, ids func(T) (string, *time.Time)) error {
+	stop := false
+	err := s.mirror.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(s.entity))
+		for _, entity := range entities {
+			id, ts := ids(entity)
+			if ts != nil && !s.mark.IsZero() && ts.Before(s.mark) {
+				stop = true
+				return nil
+			}
+			b, err := json.Marshal(entity)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(id), b); err != nil {
+				return err
+			}
+			s.seen[id] = true
+			s.result.Upserted++
+			if ts != nil && (s.maxTs == nil || ts.After(*s.maxTs)) {
+				t := *ts
+				s.maxTs = &t
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error to save page: %w", err)
+	}
+	if stop {
+		return errStop
+	}
+	return nil
+}
+
+func (s *entitySync) deleteUnseen() error {
+	return s.mirror.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(s.entity))
+		deleted := make([][]byte, 0)
+		err := bucket.ForEach(func(k, _ []byte) error {
+			if !s.seen[string(k)] {
+				deleted = append(deleted, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range deleted {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		s.result.Deleted = len(deleted)
+		return nil
+	})
+}
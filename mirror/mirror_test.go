@@ -0,0 +1,124 @@
+package mirror
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMirror(t *testing.T, server *skalintest.Server) (*Mirror, skalinsdk.Skalin) {
+	client, err := skalinsdk.New("clientId", "clientApiId", "clientApiSecret",
+		skalinsdk.WithHTTPClient(server.Client()),
+		skalinsdk.WithLogger(skalinsdk.NewNopLogger()),
+	)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	m, err := Open(filepath.Join(t.TempDir(), "skalin.db"), client)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { m.Close() })
+	return m, client
+}
+
+func seed(t *testing.T, server *skalintest.Server, kind skalintest.Kind, entities ...skalintest.Entity) {
+	for _, entity := range entities {
+		_, err := server.Seed(kind, entity)
+		assert.NoError(t, err)
+	}
+}
+
+func TestSync(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	m, client := newTestMirror(t, server)
+	seed(t, server, skalintest.Customers,
+		skalintest.Entity{"id": "c1", "refId": "1", "name": "Karnott", "lastActivityTs": "2023-01-01T00:00:00Z"},
+		skalintest.Entity{"id": "c2", "refId": "2", "name": "Skalin", "lastActivityTs": "2023-01-02T00:00:00Z"},
+		skalintest.Entity{"id": "c3", "refId": "3", "name": "Other", "lastActivityTs": "2023-01-03T00:00:00Z"},
+	)
+	seed(t, server, skalintest.Contacts, skalintest.Entity{"id": "u1", "refId": "1", "customerId": "c1", "lastActivityTs": "2023-01-01T00:00:00Z"})
+	seed(t, server, skalintest.Agreements, skalintest.Entity{"id": "a1", "refId": "1", "customerId": "c1", "startDate": "2023-01-01"})
+	seed(t, server, skalintest.Tags, skalintest.Entity{"id": "t1", "name": "Tag"})
+
+	t.Run("OK", func(t *testing.T) {
+		results, err := m.Sync()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, &SyncResult{Full: true, Upserted: 3}, results[Customers])
+		assert.Equal(t, 1, results[Tags].Upserted)
+		state, err := m.State(Customers)
+		if !assert.NoError(t, err) || !assert.NotNil(t, state.HighWaterMark) {
+			return
+		}
+		assert.Equal(t, "2023-01-03T00:00:00Z", state.HighWaterMark.UTC().Format("2006-01-02T15:04:05Z07:00"))
+
+		customer, err := m.CustomerByRefID("2")
+		if !assert.NoError(t, err) || !assert.NotNil(t, customer) {
+			return
+		}
+		assert.Equal(t, "Skalin", customer.Name)
+		contacts, err := m.ContactsOfCustomer("c1")
+		assert.NoError(t, err)
+		assert.Len(t, contacts, 1)
+		agreements, err := m.AgreementsOfCustomer("c1")
+		assert.NoError(t, err)
+		assert.Len(t, agreements, 1)
+		tag, err := m.Tag("t1")
+		assert.NoError(t, err)
+		assert.Equal(t, "Tag", tag.Name)
+		missing, err := m.Customer("unknown")
+		assert.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+
+	t.Run("Incremental", func(t *testing.T) {
+		seed(t, server, skalintest.Customers,
+			skalintest.Entity{"id": "c1", "refId": "1", "name": "Karnott 2", "lastActivityTs": "2023-01-04T00:00:00Z"},
+			skalintest.Entity{"id": "c4", "refId": "4", "name": "New", "lastActivityTs": "2023-01-05T00:00:00Z"},
+		)
+		if !assert.NoError(t, client.DeleteCustomer(skalinsdk.Customer{Id: "c2"})) {
+			return
+		}
+		result, err := m.SyncEntity(Customers, false)
+		if !assert.NoError(t, err) {
+			return
+		}
+		// c4, c1 and c3 (same lastActivityTs as the high-water mark)
+		assert.Equal(t, &SyncResult{Upserted: 3}, result)
+		customer, _ := m.Customer("c1")
+		assert.Equal(t, "Karnott 2", customer.Name)
+		// the deleted customer is only removed by a full resync
+		customer, _ = m.Customer("c2")
+		assert.NotNil(t, customer)
+
+		results, err := m.FullResync()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, &SyncResult{Full: true, Upserted: 3, Deleted: 1}, results[Customers])
+		customers, err := m.Customers(nil)
+		assert.NoError(t, err)
+		assert.Len(t, customers, 3)
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		server.InjectFault("GET /v1/contacts", skalintest.Fault{Status: http.StatusServiceUnavailable})
+		defer server.ClearFaults()
+		before, _ := m.State(Contacts)
+		results, err := m.Sync()
+		assert.Error(t, err)
+		assert.NotNil(t, results[Customers])
+		after, _ := m.State(Contacts)
+		assert.Equal(t, before, after)
+
+		_, err = m.SyncEntity("unknown", true)
+		assert.Error(t, err)
+	})
+}
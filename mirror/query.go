@@ -0,0 +1,100 @@
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Customers returns the mirrored customers for which filter returns true, all the customers if filter is nil
+func (m *Mirror) Customers(filter func(skalinsdk.Customer) bool) ([]skalinsdk.Customer, error) {
+	return list(m, Customers, filter)
+}
+
+func (m *Mirror) Contacts(filter func(skalinsdk.Contact) bool) ([]skalinsdk.Contact, error) {
+	return list(m, Contacts, filter)
+}
+
+func (m *Mirror) Agreements(filter func(skalinsdk.Agreement) bool) ([]skalinsdk.Agreement, error) {
+	return list(m, Agreements, filter)
+}
+
+func (m *Mirror) Tags(filter func(skalinsdk.Tag) bool) ([]skalinsdk.Tag, error) {
+	return list(m, Tags, filter)
+}
+
+// Customer returns the customer with the id, or nil if it is not mirrored
+func (m *Mirror) Customer(id string) (*skalinsdk.Customer, error) {
+	return get[skalinsdk.Customer](m, Customers, id)
+}
+
+func (m *Mirror) Contact(id string) (*skalinsdk.Contact, error) {
+	return get[skalinsdk.Contact](m, Contacts, id)
+}
+
+func (m *Mirror) Agreement(id string) (*skalinsdk.Agreement, error) {
+	return get[skalinsdk.Agreement](m, Agreements, id)
+}
+
+func (m *Mirror) Tag(id string) (*skalinsdk.Tag, error) {
+	return get[skalinsdk.Tag](m, Tags, id)
+}
+
+// CustomerByRefID returns the first customer with the refId, or nil if none is mirrored
+func (m *Mirror) CustomerByRefID(refID string) (*skalinsdk.Customer, error) {
+	customers, err := m.Customers(func(c skalinsdk.Customer) bool { return c.RefId == refID })
+	if err != nil || len(customers) == 0 {
+		return nil, err
+	}
+	return &customers[0], nil
+}
+
+func (m *Mirror) ContactsOfCustomer(customerID string) ([]skalinsdk.Contact, error) {
+	return m.Contacts(func(c skalinsdk.Contact) bool { return c.CustomerId != nil && *c.CustomerId == customerID })
+}
+
+func (m *Mirror) AgreementsOfCustomer(customerID string) ([]skalinsdk.Agreement, error) {
+	return m.Agreements(func(a skalinsdk.Agreement) bool { return a.CustomerId != nil && *a.CustomerId == customerID })
+}
+
+// list returns the entities in the order of their ids
+func list[T any](m *Mirror, entity Entity, filter func(T) bool) ([]T, error) {
+	result := make([]T, 0)
+	err := m.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(entity)).ForEach(func(k, v []byte) error {
+			var value T
+			if err := json.Unmarshal(v, &value); err != nil {
+				return fmt.Errorf("error to unmarshal %v %s: %w", entity, k, err)
+			}
+			if filter == nil || filter(value) {
+				result = append(result, value)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func get[T any](m *Mirror, entity Entity, id string) (*T, error) {
+	var result *T
+	err := m.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket([]byte(entity)).Get([]byte(id))
+		if v == nil {
+			return nil
+		}
+		result = new(T)
+		if err := json.Unmarshal(v, result); err != nil {
+			return fmt.Errorf("error to unmarshal %v %v: %w", entity, id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
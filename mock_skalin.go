@@ -0,0 +1,617 @@
+// Code generated by internal/mockgen from skalin.go. DO NOT EDIT.
+
+package skalinsdk
+
+import (
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTestingT is the subset of testing.TB used by the mocks
+type MockTestingT interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// MockSkalin is a mock of the Skalin interface
+type MockSkalin struct {
+	mock.Mock
+}
+
+var _ Skalin = (*MockSkalin)(nil)
+
+// NewMockSkalin creates a mock which asserts that all its expectations were met at the end of the test
+func NewMockSkalin(t MockTestingT) *MockSkalin {
+	m := new(MockSkalin)
+	m.Test(t)
+	t.Cleanup(func() { m.AssertExpectations(t) })
+	return m
+}
+
+func (m *MockSkalin) GetContacts(arg0 *GetParams) ([]Contact, error) {
+	args := m.Called(arg0)
+	var r0 []Contact
+	if v := args.Get(0); v != nil {
+		r0 = v.([]Contact)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinGetContactsCall is an expectation on MockSkalin.GetContacts
+type MockSkalinGetContactsCall struct {
+	*mock.Call
+}
+
+// ExpectGetContacts expects a call of GetContacts with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectGetContacts(arg0 interface{}) *MockSkalinGetContactsCall {
+	return &MockSkalinGetContactsCall{m.On("GetContacts", arg0)}
+}
+
+// Return sets the values returned by GetContacts
+func (c *MockSkalinGetContactsCall) Return(r0 []Contact, r1 error) *MockSkalinGetContactsCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) WalkContacts(arg0 *GetParams, arg1 func([]Contact) error) error {
+	args := m.Called(arg0, arg1)
+	return args.Error(0)
+}
+
+// MockSkalinWalkContactsCall is an expectation on MockSkalin.WalkContacts
+type MockSkalinWalkContactsCall struct {
+	*mock.Call
+}
+
+// ExpectWalkContacts expects a call of WalkContacts with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectWalkContacts(arg0 interface{}, arg1 interface{}) *MockSkalinWalkContactsCall {
+	return &MockSkalinWalkContactsCall{m.On("WalkContacts", arg0, arg1)}
+}
+
+// Return sets the values returned by WalkContacts
+func (c *MockSkalinWalkContactsCall) Return(r0 error) *MockSkalinWalkContactsCall {
+	c.Call.Return(r0)
+	return c
+}
+
+func (m *MockSkalin) SaveContact(arg0 Contact) (*Contact, error) {
+	args := m.Called(arg0)
+	var r0 *Contact
+	if v := args.Get(0); v != nil {
+		r0 = v.(*Contact)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinSaveContactCall is an expectation on MockSkalin.SaveContact
+type MockSkalinSaveContactCall struct {
+	*mock.Call
+}
+
+// ExpectSaveContact expects a call of SaveContact with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectSaveContact(arg0 interface{}) *MockSkalinSaveContactCall {
+	return &MockSkalinSaveContactCall{m.On("SaveContact", arg0)}
+}
+
+// Return sets the values returned by SaveContact
+func (c *MockSkalinSaveContactCall) Return(r0 *Contact, r1 error) *MockSkalinSaveContactCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) UpdateContact(arg0 Contact) (*Contact, error) {
+	args := m.Called(arg0)
+	var r0 *Contact
+	if v := args.Get(0); v != nil {
+		r0 = v.(*Contact)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinUpdateContactCall is an expectation on MockSkalin.UpdateContact
+type MockSkalinUpdateContactCall struct {
+	*mock.Call
+}
+
+// ExpectUpdateContact expects a call of UpdateContact with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectUpdateContact(arg0 interface{}) *MockSkalinUpdateContactCall {
+	return &MockSkalinUpdateContactCall{m.On("UpdateContact", arg0)}
+}
+
+// Return sets the values returned by UpdateContact
+func (c *MockSkalinUpdateContactCall) Return(r0 *Contact, r1 error) *MockSkalinUpdateContactCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) CreateContactForCustomer(arg0 Contact, arg1 string) (*Contact, error) {
+	args := m.Called(arg0, arg1)
+	var r0 *Contact
+	if v := args.Get(0); v != nil {
+		r0 = v.(*Contact)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinCreateContactForCustomerCall is an expectation on MockSkalin.CreateContactForCustomer
+type MockSkalinCreateContactForCustomerCall struct {
+	*mock.Call
+}
+
+// ExpectCreateContactForCustomer expects a call of CreateContactForCustomer with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectCreateContactForCustomer(arg0 interface{}, arg1 interface{}) *MockSkalinCreateContactForCustomerCall {
+	return &MockSkalinCreateContactForCustomerCall{m.On("CreateContactForCustomer", arg0, arg1)}
+}
+
+// Return sets the values returned by CreateContactForCustomer
+func (c *MockSkalinCreateContactForCustomerCall) Return(r0 *Contact, r1 error) *MockSkalinCreateContactForCustomerCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) DeleteContact(arg0 Contact) error {
+	args := m.Called(arg0)
+	return args.Error(0)
+}
+
+// MockSkalinDeleteContactCall is an expectation on MockSkalin.DeleteContact
+type MockSkalinDeleteContactCall struct {
+	*mock.Call
+}
+
+// ExpectDeleteContact expects a call of DeleteContact with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectDeleteContact(arg0 interface{}) *MockSkalinDeleteContactCall {
+	return &MockSkalinDeleteContactCall{m.On("DeleteContact", arg0)}
+}
+
+// Return sets the values returned by DeleteContact
+func (c *MockSkalinDeleteContactCall) Return(r0 error) *MockSkalinDeleteContactCall {
+	c.Call.Return(r0)
+	return c
+}
+
+func (m *MockSkalin) GetCustomers(arg0 *GetParams) ([]Customer, error) {
+	args := m.Called(arg0)
+	var r0 []Customer
+	if v := args.Get(0); v != nil {
+		r0 = v.([]Customer)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinGetCustomersCall is an expectation on MockSkalin.GetCustomers
+type MockSkalinGetCustomersCall struct {
+	*mock.Call
+}
+
+// ExpectGetCustomers expects a call of GetCustomers with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectGetCustomers(arg0 interface{}) *MockSkalinGetCustomersCall {
+	return &MockSkalinGetCustomersCall{m.On("GetCustomers", arg0)}
+}
+
+// Return sets the values returned by GetCustomers
+func (c *MockSkalinGetCustomersCall) Return(r0 []Customer, r1 error) *MockSkalinGetCustomersCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) WalkCustomers(arg0 *GetParams, arg1 func([]Customer) error) error {
+	args := m.Called(arg0, arg1)
+	return args.Error(0)
+}
+
+// MockSkalinWalkCustomersCall is an expectation on MockSkalin.WalkCustomers
+type MockSkalinWalkCustomersCall struct {
+	*mock.Call
+}
+
+// ExpectWalkCustomers expects a call of WalkCustomers with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectWalkCustomers(arg0 interface{}, arg1 interface{}) *MockSkalinWalkCustomersCall {
+	return &MockSkalinWalkCustomersCall{m.On("WalkCustomers", arg0, arg1)}
+}
+
+// Return sets the values returned by WalkCustomers
+func (c *MockSkalinWalkCustomersCall) Return(r0 error) *MockSkalinWalkCustomersCall {
+	c.Call.Return(r0)
+	return c
+}
+
+func (m *MockSkalin) SaveCustomer(arg0 Customer) (*Customer, error) {
+	args := m.Called(arg0)
+	var r0 *Customer
+	if v := args.Get(0); v != nil {
+		r0 = v.(*Customer)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinSaveCustomerCall is an expectation on MockSkalin.SaveCustomer
+type MockSkalinSaveCustomerCall struct {
+	*mock.Call
+}
+
+// ExpectSaveCustomer expects a call of SaveCustomer with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectSaveCustomer(arg0 interface{}) *MockSkalinSaveCustomerCall {
+	return &MockSkalinSaveCustomerCall{m.On("SaveCustomer", arg0)}
+}
+
+// Return sets the values returned by SaveCustomer
+func (c *MockSkalinSaveCustomerCall) Return(r0 *Customer, r1 error) *MockSkalinSaveCustomerCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) UpdateCustomer(arg0 Customer) (*Customer, error) {
+	args := m.Called(arg0)
+	var r0 *Customer
+	if v := args.Get(0); v != nil {
+		r0 = v.(*Customer)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinUpdateCustomerCall is an expectation on MockSkalin.UpdateCustomer
+type MockSkalinUpdateCustomerCall struct {
+	*mock.Call
+}
+
+// ExpectUpdateCustomer expects a call of UpdateCustomer with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectUpdateCustomer(arg0 interface{}) *MockSkalinUpdateCustomerCall {
+	return &MockSkalinUpdateCustomerCall{m.On("UpdateCustomer", arg0)}
+}
+
+// Return sets the values returned by UpdateCustomer
+func (c *MockSkalinUpdateCustomerCall) Return(r0 *Customer, r1 error) *MockSkalinUpdateCustomerCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) DeleteCustomer(arg0 Customer) error {
+	args := m.Called(arg0)
+	return args.Error(0)
+}
+
+// MockSkalinDeleteCustomerCall is an expectation on MockSkalin.DeleteCustomer
+type MockSkalinDeleteCustomerCall struct {
+	*mock.Call
+}
+
+// ExpectDeleteCustomer expects a call of DeleteCustomer with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectDeleteCustomer(arg0 interface{}) *MockSkalinDeleteCustomerCall {
+	return &MockSkalinDeleteCustomerCall{m.On("DeleteCustomer", arg0)}
+}
+
+// Return sets the values returned by DeleteCustomer
+func (c *MockSkalinDeleteCustomerCall) Return(r0 error) *MockSkalinDeleteCustomerCall {
+	c.Call.Return(r0)
+	return c
+}
+
+func (m *MockSkalin) GetAgreements(arg0 *GetParams) ([]Agreement, error) {
+	args := m.Called(arg0)
+	var r0 []Agreement
+	if v := args.Get(0); v != nil {
+		r0 = v.([]Agreement)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinGetAgreementsCall is an expectation on MockSkalin.GetAgreements
+type MockSkalinGetAgreementsCall struct {
+	*mock.Call
+}
+
+// ExpectGetAgreements expects a call of GetAgreements with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectGetAgreements(arg0 interface{}) *MockSkalinGetAgreementsCall {
+	return &MockSkalinGetAgreementsCall{m.On("GetAgreements", arg0)}
+}
+
+// Return sets the values returned by GetAgreements
+func (c *MockSkalinGetAgreementsCall) Return(r0 []Agreement, r1 error) *MockSkalinGetAgreementsCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) WalkAgreements(arg0 *GetParams, arg1 func([]Agreement) error) error {
+	args := m.Called(arg0, arg1)
+	return args.Error(0)
+}
+
+// MockSkalinWalkAgreementsCall is an expectation on MockSkalin.WalkAgreements
+type MockSkalinWalkAgreementsCall struct {
+	*mock.Call
+}
+
+// ExpectWalkAgreements expects a call of WalkAgreements with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectWalkAgreements(arg0 interface{}, arg1 interface{}) *MockSkalinWalkAgreementsCall {
+	return &MockSkalinWalkAgreementsCall{m.On("WalkAgreements", arg0, arg1)}
+}
+
+// Return sets the values returned by WalkAgreements
+func (c *MockSkalinWalkAgreementsCall) Return(r0 error) *MockSkalinWalkAgreementsCall {
+	c.Call.Return(r0)
+	return c
+}
+
+func (m *MockSkalin) SaveAgreement(arg0 Agreement) (*Agreement, error) {
+	args := m.Called(arg0)
+	var r0 *Agreement
+	if v := args.Get(0); v != nil {
+		r0 = v.(*Agreement)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinSaveAgreementCall is an expectation on MockSkalin.SaveAgreement
+type MockSkalinSaveAgreementCall struct {
+	*mock.Call
+}
+
+// ExpectSaveAgreement expects a call of SaveAgreement with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectSaveAgreement(arg0 interface{}) *MockSkalinSaveAgreementCall {
+	return &MockSkalinSaveAgreementCall{m.On("SaveAgreement", arg0)}
+}
+
+// Return sets the values returned by SaveAgreement
+func (c *MockSkalinSaveAgreementCall) Return(r0 *Agreement, r1 error) *MockSkalinSaveAgreementCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) UpdateAgreement(arg0 Agreement) (*Agreement, error) {
+	args := m.Called(arg0)
+	var r0 *Agreement
+	if v := args.Get(0); v != nil {
+		r0 = v.(*Agreement)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinUpdateAgreementCall is an expectation on MockSkalin.UpdateAgreement
+type MockSkalinUpdateAgreementCall struct {
+	*mock.Call
+}
+
+// ExpectUpdateAgreement expects a call of UpdateAgreement with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectUpdateAgreement(arg0 interface{}) *MockSkalinUpdateAgreementCall {
+	return &MockSkalinUpdateAgreementCall{m.On("UpdateAgreement", arg0)}
+}
+
+// Return sets the values returned by UpdateAgreement
+func (c *MockSkalinUpdateAgreementCall) Return(r0 *Agreement, r1 error) *MockSkalinUpdateAgreementCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) CreateAgreementForCustomer(arg0 Agreement, arg1 string) (*Agreement, error) {
+	args := m.Called(arg0, arg1)
+	var r0 *Agreement
+	if v := args.Get(0); v != nil {
+		r0 = v.(*Agreement)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinCreateAgreementForCustomerCall is an expectation on MockSkalin.CreateAgreementForCustomer
+type MockSkalinCreateAgreementForCustomerCall struct {
+	*mock.Call
+}
+
+// ExpectCreateAgreementForCustomer expects a call of CreateAgreementForCustomer with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectCreateAgreementForCustomer(arg0 interface{}, arg1 interface{}) *MockSkalinCreateAgreementForCustomerCall {
+	return &MockSkalinCreateAgreementForCustomerCall{m.On("CreateAgreementForCustomer", arg0, arg1)}
+}
+
+// Return sets the values returned by CreateAgreementForCustomer
+func (c *MockSkalinCreateAgreementForCustomerCall) Return(r0 *Agreement, r1 error) *MockSkalinCreateAgreementForCustomerCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) DeleteAgreement(arg0 Agreement) error {
+	args := m.Called(arg0)
+	return args.Error(0)
+}
+
+// MockSkalinDeleteAgreementCall is an expectation on MockSkalin.DeleteAgreement
+type MockSkalinDeleteAgreementCall struct {
+	*mock.Call
+}
+
+// ExpectDeleteAgreement expects a call of DeleteAgreement with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectDeleteAgreement(arg0 interface{}) *MockSkalinDeleteAgreementCall {
+	return &MockSkalinDeleteAgreementCall{m.On("DeleteAgreement", arg0)}
+}
+
+// Return sets the values returned by DeleteAgreement
+func (c *MockSkalinDeleteAgreementCall) Return(r0 error) *MockSkalinDeleteAgreementCall {
+	c.Call.Return(r0)
+	return c
+}
+
+func (m *MockSkalin) GetTags(arg0 *GetParams) ([]Tag, error) {
+	args := m.Called(arg0)
+	var r0 []Tag
+	if v := args.Get(0); v != nil {
+		r0 = v.([]Tag)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinGetTagsCall is an expectation on MockSkalin.GetTags
+type MockSkalinGetTagsCall struct {
+	*mock.Call
+}
+
+// ExpectGetTags expects a call of GetTags with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectGetTags(arg0 interface{}) *MockSkalinGetTagsCall {
+	return &MockSkalinGetTagsCall{m.On("GetTags", arg0)}
+}
+
+// Return sets the values returned by GetTags
+func (c *MockSkalinGetTagsCall) Return(r0 []Tag, r1 error) *MockSkalinGetTagsCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) WalkTags(arg0 *GetParams, arg1 func([]Tag) error) error {
+	args := m.Called(arg0, arg1)
+	return args.Error(0)
+}
+
+// MockSkalinWalkTagsCall is an expectation on MockSkalin.WalkTags
+type MockSkalinWalkTagsCall struct {
+	*mock.Call
+}
+
+// ExpectWalkTags expects a call of WalkTags with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectWalkTags(arg0 interface{}, arg1 interface{}) *MockSkalinWalkTagsCall {
+	return &MockSkalinWalkTagsCall{m.On("WalkTags", arg0, arg1)}
+}
+
+// Return sets the values returned by WalkTags
+func (c *MockSkalinWalkTagsCall) Return(r0 error) *MockSkalinWalkTagsCall {
+	c.Call.Return(r0)
+	return c
+}
+
+func (m *MockSkalin) GetTagByID(arg0 string) (*Tag, error) {
+	args := m.Called(arg0)
+	var r0 *Tag
+	if v := args.Get(0); v != nil {
+		r0 = v.(*Tag)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinGetTagByIDCall is an expectation on MockSkalin.GetTagByID
+type MockSkalinGetTagByIDCall struct {
+	*mock.Call
+}
+
+// ExpectGetTagByID expects a call of GetTagByID with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectGetTagByID(arg0 interface{}) *MockSkalinGetTagByIDCall {
+	return &MockSkalinGetTagByIDCall{m.On("GetTagByID", arg0)}
+}
+
+// Return sets the values returned by GetTagByID
+func (c *MockSkalinGetTagByIDCall) Return(r0 *Tag, r1 error) *MockSkalinGetTagByIDCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalin) SetLogger(arg0 logrus.FieldLogger) {
+	m.Called(arg0)
+}
+
+// MockSkalinSetLoggerCall is an expectation on MockSkalin.SetLogger
+type MockSkalinSetLoggerCall struct {
+	*mock.Call
+}
+
+// ExpectSetLogger expects a call of SetLogger with the given arguments (values or mock.Anything)
+func (m *MockSkalin) ExpectSetLogger(arg0 interface{}) *MockSkalinSetLoggerCall {
+	return &MockSkalinSetLoggerCall{m.On("SetLogger", arg0)}
+}
+
+// Return sets the values returned by SetLogger
+func (c *MockSkalinSetLoggerCall) Return() *MockSkalinSetLoggerCall {
+	c.Call.Return()
+	return c
+}
+
+// MockSkalinTracking is a mock of the SkalinTracking interface
+type MockSkalinTracking struct {
+	mock.Mock
+}
+
+var _ SkalinTracking = (*MockSkalinTracking)(nil)
+
+// NewMockSkalinTracking creates a mock which asserts that all its expectations were met at the end of the test
+func NewMockSkalinTracking(t MockTestingT) *MockSkalinTracking {
+	m := new(MockSkalinTracking)
+	m.Test(t)
+	t.Cleanup(func() { m.AssertExpectations(t) })
+	return m
+}
+
+func (m *MockSkalinTracking) Hit(arg0 HitTrack) (*http.Response, []byte, error) {
+	args := m.Called(arg0)
+	var r0 *http.Response
+	if v := args.Get(0); v != nil {
+		r0 = v.(*http.Response)
+	}
+	var r1 []byte
+	if v := args.Get(1); v != nil {
+		r1 = v.([]byte)
+	}
+	return r0, r1, args.Error(2)
+}
+
+// MockSkalinTrackingHitCall is an expectation on MockSkalinTracking.Hit
+type MockSkalinTrackingHitCall struct {
+	*mock.Call
+}
+
+// ExpectHit expects a call of Hit with the given arguments (values or mock.Anything)
+func (m *MockSkalinTracking) ExpectHit(arg0 interface{}) *MockSkalinTrackingHitCall {
+	return &MockSkalinTrackingHitCall{m.On("Hit", arg0)}
+}
+
+// Return sets the values returned by Hit
+func (c *MockSkalinTrackingHitCall) Return(r0 *http.Response, r1 []byte, r2 error) *MockSkalinTrackingHitCall {
+	c.Call.Return(r0, r1, r2)
+	return c
+}
+
+func (m *MockSkalinTracking) HitBatch(arg0 []HitTrack) (HitResults, error) {
+	args := m.Called(arg0)
+	var r0 HitResults
+	if v := args.Get(0); v != nil {
+		r0 = v.(HitResults)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinTrackingHitBatchCall is an expectation on MockSkalinTracking.HitBatch
+type MockSkalinTrackingHitBatchCall struct {
+	*mock.Call
+}
+
+// ExpectHitBatch expects a call of HitBatch with the given arguments (values or mock.Anything)
+func (m *MockSkalinTracking) ExpectHitBatch(arg0 interface{}) *MockSkalinTrackingHitBatchCall {
+	return &MockSkalinTrackingHitBatchCall{m.On("HitBatch", arg0)}
+}
+
+// Return sets the values returned by HitBatch
+func (c *MockSkalinTrackingHitBatchCall) Return(r0 HitResults, r1 error) *MockSkalinTrackingHitBatchCall {
+	c.Call.Return(r0, r1)
+	return c
+}
+
+func (m *MockSkalinTracking) HitBatchWithOptions(arg0 []HitTrack, arg1 HitBatchOptions) (HitResults, error) {
+	args := m.Called(arg0, arg1)
+	var r0 HitResults
+	if v := args.Get(0); v != nil {
+		r0 = v.(HitResults)
+	}
+	return r0, args.Error(1)
+}
+
+// MockSkalinTrackingHitBatchWithOptionsCall is an expectation on MockSkalinTracking.HitBatchWithOptions
+type MockSkalinTrackingHitBatchWithOptionsCall struct {
+	*mock.Call
+}
+
+// ExpectHitBatchWithOptions expects a call of HitBatchWithOptions with the given arguments (values or mock.Anything)
+func (m *MockSkalinTracking) ExpectHitBatchWithOptions(arg0 interface{}, arg1 interface{}) *MockSkalinTrackingHitBatchWithOptionsCall {
+	return &MockSkalinTrackingHitBatchWithOptionsCall{m.On("HitBatchWithOptions", arg0, arg1)}
+}
+
+// Return sets the values returned by HitBatchWithOptions
+func (c *MockSkalinTrackingHitBatchWithOptionsCall) Return(r0 HitResults, r1 error) *MockSkalinTrackingHitBatchWithOptionsCall {
+	c.Call.Return(r0, r1)
+	return c
+}
@@ -0,0 +1,44 @@
+package skalinsdk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMockSkalin(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		mockSkalin := NewMockSkalin(t)
+		contact := Contact{RefId: "1"}
+		mockSkalin.ExpectSaveContact(contact).Return(&Contact{Id: "2", RefId: "1"}, nil).Once()
+		mockSkalin.ExpectGetTagByID(mock.Anything).Return(nil, errors.New("tag not found"))
+
+		var skalin Skalin = mockSkalin
+		contactSaved, err := skalin.SaveContact(contact)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "2", contactSaved.Id)
+		tag, err := skalin.GetTagByID("1")
+		assert.Nil(t, tag)
+		assert.EqualError(t, err, "tag not found")
+	})
+
+	t.Run("Tracking", func(t *testing.T) {
+		mockTracking := NewMockSkalinTracking(t)
+		mockTracking.ExpectHitBatch(mock.Anything).Return(HitResults{{Index: 0}}, nil)
+
+		var tracking SkalinTracking = mockTracking
+		results, err := tracking.HitBatch([]HitTrack{{}})
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("Unmet expectations", func(t *testing.T) {
+		mockSkalin := new(MockSkalin)
+		mockSkalin.ExpectDeleteContact(mock.Anything).Return(nil)
+		assert.False(t, mockSkalin.AssertExpectations(new(testing.T)))
+	})
+}
@@ -0,0 +1,171 @@
+package skalinsdk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+const (
+	CurrencyEUR Currency = "EUR"
+	CurrencyUSD Currency = "USD"
+	CurrencyGBP Currency = "GBP"
+	CurrencyCHF Currency = "CHF"
+	CurrencyJPY Currency = "JPY"
+)
+
+// currencies without 2 minor units
+var minorUnits = map[Currency]int{
+	"JPY": 0, "KRW": 0, "CLP": 0, "ISK": 0, "VND": 0, "XAF": 0, "XOF": 0,
+	"BHD": 3, "JOD": 3, "KWD": 3, "OMR": 3, "TND": 3,
+}
+
+// MinorUnits returns the number of decimals of the currency, 2 for most currencies
+func (c Currency) MinorUnits() int {
+	if units, ok := minorUnits[c]; ok {
+		return units
+	}
+	return 2
+}
+
+var (
+	ErrCurrencyMismatch = errors.New("currencies mismatch")
+	// ErrFractionalAmount is returned when an amount with decimals is sent, Skalin only stores whole units
+	ErrFractionalAmount = errors.New("amount is not a whole number of units")
+	ErrDivisionByZero   = errors.New("division by zero")
+)
+
+// Money is an amount in the minor units of its currency, like cents, so there is no rounding of floats.
+// An amount without currency has 2 minor units
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+// NewMoney returns an amount of whole units of the currency, like euros
+func NewMoney(units int64, currency Currency) Money {
+	return Money{Amount: units * pow10(currency.MinorUnits()), Currency: currency}
+}
+
+// ParseMoney parses a decimal amount like `99.90`. It returns an error if the amount has more decimals than the currency
+func ParseMoney(s string, currency Currency) (Money, error) {
+	amount, err := parseDecimal(strings.TrimSpace(s), currency.MinorUnits())
+	if err != nil {
+		return Money{}, fmt.Errorf("error to parse amount %q in %v: %w", s, currency, err)
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Add returns the sum of the amounts, the zero value without currency can be added to any currency
+func (m Money) Add(o Money) (Money, error) {
+	currency := m.Currency
+	switch {
+	case currency == "":
+		currency = o.Currency
+	case o.Currency != "" && o.Currency != currency:
+		return Money{}, fmt.Errorf("error to add %v to %v: %w", o, m, ErrCurrencyMismatch)
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: currency}, nil
+}
+
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+// Div divides the amount, truncated to the minor unit
+func (m Money) Div(n int64) (Money, error) {
+	if n == 0 {
+		return Money{}, fmt.Errorf("error to divide %v: %w", m, ErrDivisionByZero)
+	}
+	return Money{Amount: m.Amount / n, Currency: m.Currency}, nil
+}
+
+// In returns the amount without currency in the currency, an amount already in a currency must be in the same one
+func (m Money) In(currency Currency) (Money, error) {
+	switch m.Currency {
+	case currency:
+		return m, nil
+	case "":
+		return ParseMoney(m.Decimal(), currency)
+	}
+	return Money{}, fmt.Errorf("error to convert %v to %v: %w", m, currency, ErrCurrencyMismatch)
+}
+
+// Decimal returns the amount in units of the currency, like `99.90`, or `100` for a whole amount
+func (m Money) Decimal() string {
+	units := m.Currency.MinorUnits()
+	if m.Amount%pow10(units) == 0 {
+		return strconv.FormatInt(m.Amount/pow10(units), 10)
+	}
+	sign, amount := "", m.Amount
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	p := pow10(units)
+	return fmt.Sprintf("%v%d.%0*d", sign, amount/p, units, amount%p)
+}
+
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + string(m.Currency)
+}
+
+// MarshalJSON writes the amount as an integer number of units, the format of Skalin.
+// The currency is checked by the client before the entity is sent, see WithAccountCurrency
+func (m Money) MarshalJSON() ([]byte, error) {
+	p := pow10(m.Currency.MinorUnits())
+	if m.Amount%p != 0 {
+		return nil, fmt.Errorf("error to marshal %v: %w", m, ErrFractionalAmount)
+	}
+	return []byte(strconv.FormatInt(m.Amount/p, 10)), nil
+}
+
+// UnmarshalJSON reads a number of units, without currency: the client sets the currency of the account on the amounts read
+func (m *Money) UnmarshalJSON(b []byte) error {
+	money, err := ParseMoney(string(bytes.Trim(b, `"`)), "")
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
+
+// parseDecimal returns the decimal number in minor units, without going through a float
+func parseDecimal(s string, units int) (int64, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	integer, fraction, _ := strings.Cut(digits, ".")
+	if integer == "" || strings.Trim(integer+fraction, "0123456789") != "" {
+		return 0, errors.New("invalid amount")
+	}
+	if fraction = strings.TrimRight(fraction, "0"); len(fraction) > units {
+		return 0, fmt.Errorf("more than %v decimals", units)
+	}
+	fraction += strings.Repeat("0", units-len(fraction))
+	amount, err := strconv.ParseInt(integer+fraction, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if strings.HasPrefix(s, "-") {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
@@ -0,0 +1,125 @@
+package skalinsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency Currency
+		expected int64
+		decimal  string
+	}{
+		{"Whole", "100", CurrencyEUR, 10000, "100"},
+		{"Cents", "99.9", CurrencyEUR, 9990, "99.90"},
+		{"Trailing zeros", "10.500", CurrencyEUR, 1050, "10.50"},
+		{"Negative", "-0.05", CurrencyEUR, -5, "-0.05"},
+		{"Without minor units", "1500", CurrencyJPY, 1500, "1500"},
+		{"Three minor units", "1.234", "KWD", 1234, "1.234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseMoney(tt.amount, tt.currency)
+			if assert.NoError(t, err) {
+				assert.Equal(t, Money{Amount: tt.expected, Currency: tt.currency}, m)
+				assert.Equal(t, tt.decimal, m.Decimal())
+			}
+		})
+	}
+
+	t.Run("With error", func(t *testing.T) {
+		for _, amount := range []string{"", "1.2.3", "1e3", "12,5", "0.001"} {
+			_, err := ParseMoney(amount, CurrencyEUR)
+			assert.Error(t, err, amount)
+		}
+		_, err := ParseMoney("1.5", CurrencyJPY)
+		assert.Error(t, err)
+	})
+}
+
+func TestMoneyAdd(t *testing.T) {
+	sum, err := Money{}.Add(NewMoney(1, CurrencyEUR))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1 EUR", sum.String())
+	}
+	_, err = sum.Add(NewMoney(1, CurrencyUSD))
+	assert.True(t, errors.Is(err, ErrCurrencyMismatch), "error %v", err)
+}
+
+func TestMoneyDiv(t *testing.T) {
+	quotient, err := NewMoney(100, CurrencyEUR).Div(3)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "33.33 EUR", quotient.String())
+	}
+	_, err = NewMoney(100, CurrencyEUR).Div(0)
+	assert.True(t, errors.Is(err, ErrDivisionByZero), "error %v", err)
+}
+
+func TestMoneyJSON(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		// the amounts are read without currency, with 2 decimals
+		var agreement Agreement
+		err := json.Unmarshal([]byte(`{"mrr":100,"fee":1234.5}`), &agreement)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, Money{Amount: 10000}, *agreement.Mrr)
+		assert.Equal(t, Money{Amount: 123450}, *agreement.Fee)
+
+		mrr, fee := NewMoney(100, CurrencyEUR), NewMoney(1500, CurrencyJPY)
+		b, err := json.Marshal(Agreement{Mrr: &mrr, Fee: &fee})
+		if assert.NoError(t, err) {
+			assert.JSONEq(t, `{"mrr":100,"fee":1500}`, string(b))
+		}
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		// Skalin only stores whole units
+		_, err := json.Marshal(Agreement{Mrr: eur("99.90")})
+		assert.True(t, errors.Is(err, ErrFractionalAmount), "error %v", err)
+
+		var agreement Agreement
+		err = json.Unmarshal([]byte(`{"mrr":10.001}`), &agreement)
+		assert.Error(t, err)
+	})
+}
+
+func TestAccountCurrency(t *testing.T) {
+	response := []byte(`{"status":"success","data":[{"id":"1","mrr":1500}]}`)
+
+	t.Run("OK", func(t *testing.T) {
+		for _, currency := range []Currency{"", CurrencyUSD, CurrencyJPY} {
+			mockApi := new(MockAPI)
+			mockApi.On("send", http.MethodGet, BuildUrl(SAVE_AGREEMENT_PATH), jsonContentType,
+				mock.Anything, mock.Anything, mock.Anything, http.StatusOK).Return(nil, response, nil).Once()
+			skalinAPI := &skalinAPI{api: mockApi, currency: currency}
+			agreements, err := skalinAPI.GetAgreements(nil)
+			if assert.NoError(t, err) && assert.Len(t, agreements, 1) {
+				assert.Equal(t, NewMoney(1500, skalinAPI.accountCurrency()), *agreements[0].Mrr, currency)
+			}
+		}
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		// the amount is rejected before the request is sent
+		skalinAPI := &skalinAPI{api: new(MockAPI), currency: CurrencyUSD}
+		_, err := skalinAPI.SaveAgreement(Agreement{RefId: "1", Mrr: eur("10")})
+		assert.True(t, errors.Is(err, ErrCurrencyMismatch), "error %v", err)
+	})
+}
+
+func eur(amount string) *Money {
+	m, err := ParseMoney(amount, CurrencyEUR)
+	if err != nil {
+		panic(err)
+	}
+	return &m
+}
@@ -0,0 +1,76 @@
+package skalinsdk
+
+import "context"
+
+// ListObserver is notified when a Get* method fetches a list, and when it fetches each page of the list.
+// The returned context is used for the requests of the list or the page,
+// so the middlewares can read the values set by the observer (like a tracing span)
+type ListObserver interface {
+	StartList(ctx context.Context, path string) (context.Context, func(pages int, err error))
+	StartPage(ctx context.Context, path string, page int) (context.Context, func(count int, err error))
+}
+
+type HitStatus string
+
+const (
+	HitSent    HitStatus = "sent"
+	HitDropped HitStatus = "dropped" // the hit is invalid or was already sent by the tracker
+	HitFailed  HitStatus = "failed"  // the hit was not sent, even after retries
+)
+
+// HitObserver is notified of the hits handled by the tracker.
+// Every queued hit is done once, so the hits waiting to be sent are the queued hits not done yet
+type HitObserver interface {
+	HitQueued(count int)
+	HitDone(status HitStatus, attempts int)
+}
+
+// startList notifies the list observers, the returned function must be called at the end of the list
+func (s *skalinAPI) startList(ctx context.Context, path string) (context.Context, func(pages int, err error)) {
+	ends := make([]func(int, error), 0, len(s.listObservers))
+	for _, observer := range s.listObservers {
+		var end func(int, error)
+		ctx, end = observer.StartList(ctx, path)
+		ends = append(ends, end)
+	}
+	return ctx, func(pages int, err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](pages, err)
+		}
+	}
+}
+
+// startPage notifies the list observers, the returned function must be called when the page is fetched
+func (s *skalinAPI) startPage(ctx context.Context, path string, page int) (context.Context, func(count int, err error)) {
+	ends := make([]func(int, error), 0, len(s.listObservers))
+	for _, observer := range s.listObservers {
+		var end func(int, error)
+		ctx, end = observer.StartPage(ctx, path, page)
+		ends = append(ends, end)
+	}
+	return ctx, func(count int, err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](count, err)
+		}
+	}
+}
+
+// withContext returns the API to use for the requests of ctx
+func (s *skalinAPI) withContext(ctx context.Context) API {
+	if api, ok := s.api.(interface{ withContext(context.Context) API }); ok {
+		return api.withContext(ctx)
+	}
+	return s.api
+}
+
+func (a skalinTracker) hitQueued(count int) {
+	for _, observer := range a.hitObservers {
+		observer.HitQueued(count)
+	}
+}
+
+func (a skalinTracker) hitDone(status HitStatus, attempts int) {
+	for _, observer := range a.hitObservers {
+		observer.HitDone(status, attempts)
+	}
+}
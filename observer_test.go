@@ -0,0 +1,61 @@
+package skalinsdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+)
+
+type pageKey struct{}
+
+// recordObserver keeps the pages fetched, and checks that the requests of a page use its context
+type recordObserver struct {
+	pages []int
+	lists []int
+}
+
+func (o *recordObserver) StartList(ctx context.Context, path string) (context.Context, func(pages int, err error)) {
+	return ctx, func(pages int, err error) {
+		o.lists = append(o.lists, pages)
+	}
+}
+
+func (o *recordObserver) StartPage(ctx context.Context, path string, page int) (context.Context, func(count int, err error)) {
+	return context.WithValue(ctx, pageKey{}, page), func(count int, err error) {
+		o.pages = append(o.pages, count)
+	}
+}
+
+func TestListObserver(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	for _, refID := range []string{"1", "2", "3"} {
+		_, err := server.Seed(skalintest.Tags, skalintest.Entity{"refId": refID})
+		assert.NoError(t, err)
+	}
+	observer := &recordObserver{}
+	requestPages := make([]interface{}, 0)
+	skalinApi, err := New("clientId", "clientApiId", "clientApiSecret",
+		WithHTTPClient(server.Client()),
+		WithListObserver(observer),
+		WithMiddlewares(func(next Handler) Handler {
+			return func(req *Request) (*Response, error) {
+				requestPages = append(requestPages, req.Context.Value(pageKey{}))
+				return next(req)
+			}
+		}),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	size := 2
+	tags, err := skalinApi.GetTags(&GetParams{Size: &size})
+	assert.NoError(t, err)
+	assert.Len(t, tags, 3)
+	assert.Equal(t, []int{2, 1}, observer.pages)
+	assert.Equal(t, []int{2}, observer.lists)
+	// the first request is the authentication
+	assert.Equal(t, []interface{}{nil, 1, 2}, requestPages)
+}
@@ -0,0 +1,98 @@
+package skalinsdk
+
+import "net/http"
+
+// Option configures the client created by New or NewTracker
+type Option func(*SkalinAPI)
+
+// WithHTTPClient sets the HTTP client used to call Skalin (default is http.DefaultClient).
+// It can be used to set timeouts, a proxy or a fake Skalin server in tests
+func WithHTTPClient(client *http.Client) Option {
+	return func(a *SkalinAPI) {
+		a.httpClient = client
+	}
+}
+
+// WithLogger sets the logger of the client (Log by default)
+func WithLogger(logger Logger) Option {
+	return func(a *SkalinAPI) {
+		a.logger = logger
+	}
+}
+
+// WithLogRedaction enables or disables the redaction of tokens, secrets, emails and phone numbers in the logs.
+// Redaction is enabled by default
+func WithLogRedaction(enabled bool) Option {
+	return func(a *SkalinAPI) {
+		a.disableLogRedaction = !enabled
+	}
+}
+
+// WithMiddlewares adds middlewares after the default ones (see DefaultMiddlewares).
+// The first middleware is the outermost one
+func WithMiddlewares(middlewares ...Middleware) Option {
+	return func(a *SkalinAPI) {
+		a.middlewares = append(a.middlewares, middlewares...)
+	}
+}
+
+// WithMiddlewareChain replaces the whole middleware chain, including the default middlewares.
+// Use DefaultMiddlewares or the built-in middlewares to keep, reorder or replace them
+func WithMiddlewareChain(middlewares ...Middleware) Option {
+	return func(a *SkalinAPI) {
+		a.middlewares = append([]Middleware(nil), middlewares...)
+		a.replaceMiddlewares = true
+	}
+}
+
+// WithListObserver adds an observer of the lists and pages fetched by the Get* methods
+func WithListObserver(observer ListObserver) Option {
+	return func(a *SkalinAPI) {
+		a.listObservers = append(a.listObservers, observer)
+	}
+}
+
+// WithHitObserver adds an observer of the hits handled by the tracker
+func WithHitObserver(observer HitObserver) Option {
+	return func(a *SkalinAPI) {
+		a.hitObservers = append(a.hitObservers, observer)
+	}
+}
+
+// WithEnumMode sets the validation of the enum values of the entities sent by the client, EnumStrict by default
+func WithEnumMode(mode EnumMode) Option {
+	return func(a *SkalinAPI) {
+		a.enumMode = mode
+	}
+}
+
+// WithCustomerStages sets the stages of the Skalin account, the other stages are then unknown values.
+// Without stages, any stage is sent
+func WithCustomerStages(stages ...CustomerStage) Option {
+	return func(a *SkalinAPI) {
+		a.customerStages = append(a.customerStages, stages...)
+	}
+}
+
+// WithAccountCurrency sets the currency of the amounts of the Skalin account, EUR by default.
+// Skalin only stores the amounts, so the amounts read are in this currency and the amounts sent must be in this currency
+func WithAccountCurrency(currency Currency) Option {
+	return func(a *SkalinAPI) {
+		a.currency = currency
+	}
+}
+
+// WithCustomAttributes sets the keys of the custom attributes of the Skalin account. The fields read with these keys
+// are kept in the CustomAttributes of the customers and contacts; without keys, no field is read as a custom attribute,
+// so the other fields of the responses (ids, timestamps...) are never sent back by an update
+func WithCustomAttributes(keys ...string) Option {
+	return func(a *SkalinAPI) {
+		a.customAttributes = append(a.customAttributes, keys...)
+	}
+}
+
+// WithCircuitBreakers adds the circuit breakers after the middlewares already added.
+// The same breakers can be shared by a client and a tracker
+func WithCircuitBreakers(breakers *CircuitBreakers) Option {
+	return WithMiddlewares(breakers.Middleware())
+}
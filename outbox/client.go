@@ -0,0 +1,149 @@
+package outbox
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+)
+
+// ErrQueued is returned by the wrapped client and tracker when a write was queued in the outbox instead of being sent
+var ErrQueued = errors.New("skalin is unreachable, the write is queued in the outbox")
+
+// IsUnreachable returns true if the error means that Skalin could not handle the request:
+// network error, open circuit breaker, rate limit or server error
+func IsUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, skalinsdk.ErrCircuitOpen) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var httpErr *skalinsdk.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// Client wraps a client so the writes failing because Skalin is unreachable are queued in the outbox.
+// In this case, the error wraps ErrQueued
+func (o *Outbox) Client(client skalinsdk.Skalin) skalinsdk.Skalin {
+	return &outboxClient{Skalin: client, outbox: o}
+}
+
+// Tracker wraps a tracker so the hits failing because Skalin is unreachable are queued in the outbox.
+// In this case, the error wraps ErrQueued
+func (o *Outbox) Tracker(tracker skalinsdk.SkalinTracking) skalinsdk.SkalinTracking {
+	return &outboxTracker{SkalinTracking: tracker, outbox: o}
+}
+
+type outboxClient struct {
+	skalinsdk.Skalin
+	outbox *Outbox
+}
+
+// queue adds the failed write to the outbox if Skalin is unreachable
+func (o *Outbox) queue(op Op, value interface{}, err error) error {
+	if !IsUnreachable(err) {
+		return err
+	}
+	if _, addErr := o.Add(op, value); addErr != nil {
+		return fmt.Errorf("%w, and error to queue it in the outbox: %v", err, addErr)
+	}
+	return fmt.Errorf("%w: %v", ErrQueued, err)
+}
+
+func (c *outboxClient) SaveContact(contact skalinsdk.Contact) (*skalinsdk.Contact, error) {
+	saved, err := c.Skalin.SaveContact(contact)
+	if err != nil {
+		return nil, c.outbox.queue(OpSaveContact, contact, err)
+	}
+	return saved, nil
+}
+
+func (c *outboxClient) UpdateContact(contact skalinsdk.Contact) (*skalinsdk.Contact, error) {
+	updated, err := c.Skalin.UpdateContact(contact)
+	if err != nil {
+		return nil, c.outbox.queue(OpUpdateContact, contact, err)
+	}
+	return updated, nil
+}
+
+func (c *outboxClient) SaveCustomer(customer skalinsdk.Customer) (*skalinsdk.Customer, error) {
+	saved, err := c.Skalin.SaveCustomer(customer)
+	if err != nil {
+		return nil, c.outbox.queue(OpSaveCustomer, customer, err)
+	}
+	return saved, nil
+}
+
+func (c *outboxClient) SaveAgreement(agreement skalinsdk.Agreement) (*skalinsdk.Agreement, error) {
+	saved, err := c.Skalin.SaveAgreement(agreement)
+	if err != nil {
+		return nil, c.outbox.queue(OpSaveAgreement, agreement, err)
+	}
+	return saved, nil
+}
+
+func (c *outboxClient) UpdateAgreement(agreement skalinsdk.Agreement) (*skalinsdk.Agreement, error) {
+	updated, err := c.Skalin.UpdateAgreement(agreement)
+	if err != nil {
+		return nil, c.outbox.queue(OpUpdateAgreement, agreement, err)
+	}
+	return updated, nil
+}
+
+type outboxTracker struct {
+	skalinsdk.SkalinTracking
+	outbox *Outbox
+}
+
+// Hit queues the hit with the timestamp and the event id of the first attempt, so Skalin can dedup the replay
+func (t *outboxTracker) Hit(ht skalinsdk.HitTrack) (*http.Response, []byte, error) {
+	ht = ht.WithEventID()
+	res, body, err := t.SkalinTracking.Hit(ht)
+	if err != nil {
+		return res, body, t.outbox.queue(OpHit, ht, err)
+	}
+	return res, body, nil
+}
+
+func (t *outboxTracker) HitBatch(hits []skalinsdk.HitTrack) (skalinsdk.HitResults, error) {
+	return t.HitBatchWithOptions(hits, skalinsdk.DefaultHitBatchOptions)
+}
+
+// HitBatchWithOptions queues the hits of the batch still failing after the retries
+func (t *outboxTracker) HitBatchWithOptions(hits []skalinsdk.HitTrack, opts skalinsdk.HitBatchOptions) (skalinsdk.HitResults, error) {
+	// the timestamps and event ids are set before the first attempt, so the queued hits keep them
+	stamped := make([]skalinsdk.HitTrack, len(hits))
+	for i, hit := range hits {
+		stamped[i] = hit.WithEventID()
+	}
+	results, err := t.SkalinTracking.HitBatchWithOptions(stamped, opts)
+	if err == nil || results == nil {
+		return results, err
+	}
+	queued := 0
+	for _, index := range results.Failed() {
+		result := &results[index]
+		if !IsUnreachable(result.Err) {
+			continue
+		}
+		if _, addErr := t.outbox.Add(OpHit, stamped[result.Index]); addErr != nil {
+			return results, fmt.Errorf("%w, and error to queue hit %v in the outbox: %v", err, result.Index, addErr)
+		}
+		result.Err = fmt.Errorf("%w: %v", ErrQueued, result.Err)
+		queued++
+	}
+	if queued == 0 {
+		return results, err
+	}
+	return results, fmt.Errorf("%w: %v hit(s): %v", ErrQueued, queued, err)
+}
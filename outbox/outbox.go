@@ -0,0 +1,388 @@
+// Package outbox keeps the writes to Skalin which could not be sent, in a local append-only JSON Lines file,
+// and replays them in order when Skalin is back.
+//
+//	box, err := outbox.Open("skalin-outbox.jsonl")
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer box.Close()
+//	client := box.Client(skalinApi) // the writes failing because Skalin is unreachable are queued
+//	...
+//	report, err := box.Replay(skalinApi, tracker, outbox.DefaultReplayOptions)
+//
+// A queued entity write takes the place of the previous pending write of the same entity (same refId, or same id for updates),
+// so the replay order is kept and only the latest state is sent: a save replaces the pending write,
+// an update (a partial write) is merged into it
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+)
+
+// Op is the write to replay
+type Op string
+
+const (
+	OpSaveContact     Op = "contact.save"
+	OpUpdateContact   Op = "contact.update"
+	OpSaveCustomer    Op = "customer.save"
+	OpSaveAgreement   Op = "agreement.save"
+	OpUpdateAgreement Op = "agreement.update"
+	OpHit             Op = "hit"
+)
+
+// Entry is a pending write
+type Entry struct {
+	Seq       uint64          `json:"seq"`
+	Op        Op              `json:"op"`
+	Key       string          `json:"key,omitempty"` // entity written, used to keep only the latest state (empty for the hits)
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Attempts  int             `json:"attempts,omitempty"`
+	LastError string          `json:"lastError,omitempty"`
+}
+
+// record is a line of the outbox file
+type record struct {
+	Add    *Entry   `json:"add,omitempty"`
+	Remove uint64   `json:"remove,omitempty"`
+	Fail   *failure `json:"fail,omitempty"`
+}
+
+type failure struct {
+	Seq      uint64 `json:"seq"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error"`
+}
+
+// Outbox is safe for concurrent use, but a file must only be opened by one Outbox at a time
+type Outbox struct {
+	path string
+	now  func() time.Time
+
+	mu      sync.Mutex
+	file    *os.File
+	entries []Entry
+	lastSeq uint64
+}
+
+// Open loads the outbox file at path, it is created if it does not exist
+func Open(path string) (*Outbox, error) {
+	o := &Outbox{path: path, now: time.Now}
+	if err := o.load(); err != nil {
+		return nil, err
+	}
+	// the file is rewritten with the pending entries only
+	if err := o.compact(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *Outbox) load() error {
+	b, err := os.ReadFile(o.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	lines := bytes.Split(b, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil {
+			// the last line can be half written if the process stopped while writing it
+			if i == len(lines)-1 {
+				break
+			}
+			return fmt.Errorf("error to unmarshal line %v of outbox %v: %w", i+1, o.path, err)
+		}
+		o.apply(r)
+	}
+	return nil
+}
+
+// apply updates the entries in memory with a record
+func (o *Outbox) apply(r record) {
+	switch {
+	case r.Add != nil:
+		o.add(*r.Add)
+	case r.Remove != 0:
+		for i, entry := range o.entries {
+			if entry.Seq == r.Remove {
+				o.entries = append(o.entries[:i], o.entries[i+1:]...)
+				break
+			}
+		}
+	case r.Fail != nil:
+		for i, entry := range o.entries {
+			if entry.Seq == r.Fail.Seq {
+				o.entries[i].Attempts = r.Fail.Attempts
+				o.entries[i].LastError = r.Fail.Error
+				break
+			}
+		}
+	}
+}
+
+// add appends the entry, or puts it at the place of the pending write of the same entity
+// so the writes queued after it are still replayed after it
+func (o *Outbox) add(entry Entry) {
+	if entry.Seq > o.lastSeq {
+		o.lastSeq = entry.Seq
+	}
+	if entry.Key != "" {
+		for i, pending := range o.entries {
+			if pending.Key != entry.Key {
+				continue
+			}
+			if entry.Op.isUpdate() {
+				entry.Op = pending.Op
+				entry.Payload = mergePayloads(pending.Payload, entry.Payload)
+			}
+			o.entries[i] = entry
+			return
+		}
+	}
+	o.entries = append(o.entries, entry)
+}
+
+func (op Op) isUpdate() bool {
+	return op == OpUpdateContact || op == OpUpdateAgreement
+}
+
+// mergePayloads sets the fields of the update on the pending payload.
+// The payloads are marshalled entities, so they are JSON objects and the update only has the fields set
+func mergePayloads(pending, update json.RawMessage) json.RawMessage {
+	var fields, updateFields map[string]json.RawMessage
+	if json.Unmarshal(pending, &fields) != nil || json.Unmarshal(update, &updateFields) != nil {
+		return update
+	}
+	for key, value := range updateFields {
+		fields[key] = value
+	}
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return update
+	}
+	return merged
+}
+
+// write appends the record to the file, then applies it
+func (o *Outbox) write(r record) error {
+	if o.file == nil {
+		return errors.New("outbox is closed")
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := o.file.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("error to write outbox %v: %w", o.path, err)
+	}
+	if err := o.file.Sync(); err != nil {
+		return fmt.Errorf("error to sync outbox %v: %w", o.path, err)
+	}
+	o.apply(r)
+	return nil
+}
+
+// compact rewrites the file with the pending entries, in a temporary file renamed afterwards,
+// then reopens it to append the next records
+func (o *Outbox) compact() error {
+	if o.file != nil {
+		if err := o.file.Close(); err != nil {
+			return err
+		}
+		o.file = nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(o.path), filepath.Base(o.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	for i := range o.entries {
+		b, err := json.Marshal(record{Add: &o.entries[i]})
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+		_, _ = w.Write(append(b, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), o.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	o.file, err = os.OpenFile(o.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	return err
+}
+
+// Add queues a write. value is the entity of the op (skalinsdk.Contact, skalinsdk.Customer, skalinsdk.Agreement or skalinsdk.HitTrack)
+func (o *Outbox) Add(op Op, value interface{}) (Entry, error) {
+	key, value, err := entryKey(op, value, o.now())
+	if err != nil {
+		return Entry{}, err
+	}
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return Entry{}, fmt.Errorf("error to marshal %v for outbox: %w", op, err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entry := Entry{
+		Seq:       o.lastSeq + 1,
+		Op:        op,
+		Key:       key,
+		Payload:   payload,
+		CreatedAt: o.now().UTC(),
+	}
+	if err := o.write(record{Add: &entry}); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// entryKey checks the type of the value and returns the key of the entity.
+// The hits without timestamp get the time of the call, and an event id derived from it
+func entryKey(op Op, value interface{}, now time.Time) (string, interface{}, error) {
+	switch op {
+	case OpSaveContact, OpUpdateContact:
+		contact, ok := value.(skalinsdk.Contact)
+		if !ok {
+			return "", nil, fmt.Errorf("%v expects a skalinsdk.Contact, got %T", op, value)
+		}
+		return entityKey("contact", contact.RefId, contact.Id), contact, nil
+	case OpSaveCustomer:
+		customer, ok := value.(skalinsdk.Customer)
+		if !ok {
+			return "", nil, fmt.Errorf("%v expects a skalinsdk.Customer, got %T", op, value)
+		}
+		return entityKey("customer", customer.RefId, customer.Id), customer, nil
+	case OpSaveAgreement, OpUpdateAgreement:
+		agreement, ok := value.(skalinsdk.Agreement)
+		if !ok {
+			return "", nil, fmt.Errorf("%v expects a skalinsdk.Agreement, got %T", op, value)
+		}
+		return entityKey("agreement", agreement.RefId, agreement.Id), agreement, nil
+	case OpHit:
+		hit, ok := value.(skalinsdk.HitTrack)
+		if !ok {
+			return "", nil, fmt.Errorf("%v expects a skalinsdk.HitTrack, got %T", op, value)
+		}
+		if hit.Ts == nil {
+			ts := now
+			hit.Ts = &ts
+		}
+		hit = hit.WithEventID()
+		// time.Location can't be serialized, it is replaced by its name
+		if hit.Location != nil {
+			if hit.TimeZone == "" {
+				timeZone, err := timeZoneName(hit.Location, *hit.Ts)
+				if err != nil {
+					return "", nil, err
+				}
+				hit.TimeZone = timeZone
+			}
+			hit.Location = nil
+		}
+		return "", hit, nil
+	}
+	return "", nil, fmt.Errorf("unknown outbox op %q", op)
+}
+
+// timeZoneName returns the name of the location, if the location loaded from this name has the same offset at ts.
+// A time.FixedZone has no IANA name, so the local time of the hit would be lost on replay
+func timeZoneName(location *time.Location, ts time.Time) (string, error) {
+	name := location.String()
+	loaded, err := time.LoadLocation(name)
+	if err != nil {
+		return "", fmt.Errorf("error to queue hit with location %q, use an IANA timezone: %w", name, err)
+	}
+	_, offset := ts.In(location).Zone()
+	if _, loadedOffset := ts.In(loaded).Zone(); loadedOffset != offset {
+		return "", fmt.Errorf("error to queue hit with location %q, its offset differs from the timezone of this name", name)
+	}
+	return name, nil
+}
+
+func entityKey(kind, refID, id string) string {
+	if refID != "" {
+		return kind + ":refId:" + refID
+	}
+	if id != "" {
+		return kind + ":id:" + id
+	}
+	return ""
+}
+
+// List returns the pending entries, in the order they will be replayed
+func (o *Outbox) List() []Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Entry(nil), o.entries...)
+}
+
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.entries)
+}
+
+// Remove deletes the pending entries with the given sequence numbers
+func (o *Outbox) Remove(seqs ...uint64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, seq := range seqs {
+		if err := o.write(record{Remove: seq}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Purge deletes all the pending entries
+func (o *Outbox) Purge() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = nil
+	return o.compact()
+}
+
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return nil
+	}
+	err := o.file.Close()
+	o.file = nil
+	return err
+}
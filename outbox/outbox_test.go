@@ -0,0 +1,251 @@
+package outbox_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/outbox"
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func openOutbox(t *testing.T) (*outbox.Outbox, string) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	box, err := outbox.Open(path)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	t.Cleanup(func() { box.Close() })
+	return box, path
+}
+
+func newTestHit() skalinsdk.HitTrack {
+	identityID := "identity"
+	return skalinsdk.HitTrack{
+		Action:    skalinsdk.HitActionEvent,
+		VisitorID: "1111111111111111",
+		VisitID:   "1234567890123456",
+		Identity:  skalinsdk.HitIdentity{ID: &identityID},
+		Event:     &skalinsdk.HitEvent{Name: "event", EventName: "event"},
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		box, path := openOutbox(t)
+		_, err := box.Add(outbox.OpSaveContact, skalinsdk.Contact{RefId: "1", Email: "old@karnott.fr"})
+		assert.NoError(t, err)
+		_, err = box.Add(outbox.OpSaveCustomer, skalinsdk.Customer{RefId: "1"})
+		assert.NoError(t, err)
+		_, err = box.Add(outbox.OpHit, newTestHit())
+		assert.NoError(t, err)
+		// only the latest state of the contact is kept
+		_, err = box.Add(outbox.OpSaveContact, skalinsdk.Contact{RefId: "1", Email: "new@karnott.fr"})
+		assert.NoError(t, err)
+
+		entries := box.List()
+		if !assert.Len(t, entries, 3) {
+			return
+		}
+		// the contact keeps its place in the replay order
+		assert.Equal(t, outbox.OpSaveContact, entries[0].Op)
+		assert.Contains(t, string(entries[0].Payload), "new@karnott.fr")
+		assert.Equal(t, outbox.OpSaveCustomer, entries[1].Op)
+		assert.Equal(t, outbox.OpHit, entries[2].Op)
+
+		assert.NoError(t, box.Remove(entries[0].Seq))
+		assert.NoError(t, box.Close())
+
+		reopened, err := outbox.Open(path)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer reopened.Close()
+		assert.Equal(t, entries[1:], reopened.List())
+		// the sequence numbers are never reused, the last one is the contact written last
+		entry, err := reopened.Add(outbox.OpSaveCustomer, skalinsdk.Customer{RefId: "2"})
+		assert.NoError(t, err)
+		assert.Equal(t, entries[0].Seq+1, entry.Seq)
+
+		assert.NoError(t, reopened.Purge())
+		assert.Equal(t, 0, reopened.Len())
+	})
+
+	t.Run("Update merged into the pending save", func(t *testing.T) {
+		box, path := openOutbox(t)
+		_, err := box.Add(outbox.OpSaveContact, skalinsdk.Contact{RefId: "1", Email: "contact@karnott.fr", LastName: "Old"})
+		assert.NoError(t, err)
+		_, err = box.Add(outbox.OpUpdateContact, skalinsdk.Contact{RefId: "1", LastName: "New"})
+		assert.NoError(t, err)
+
+		entries := box.List()
+		if !assert.Len(t, entries, 1) {
+			return
+		}
+		assert.Equal(t, outbox.OpSaveContact, entries[0].Op)
+		assert.JSONEq(t, `{"refId":"1","email":"contact@karnott.fr","lastName":"New"}`, string(entries[0].Payload))
+
+		assert.NoError(t, box.Close())
+		reopened, err := outbox.Open(path)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer reopened.Close()
+		assert.Equal(t, entries, reopened.List())
+	})
+
+	t.Run("Half written line", func(t *testing.T) {
+		box, path := openOutbox(t)
+		_, err := box.Add(outbox.OpSaveCustomer, skalinsdk.Customer{RefId: "1"})
+		assert.NoError(t, err)
+		assert.NoError(t, box.Close())
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, _ = f.WriteString(`{"add":{"seq":2,"op":"cust`)
+		f.Close()
+
+		reopened, err := outbox.Open(path)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer reopened.Close()
+		assert.Equal(t, 1, reopened.Len())
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		box, _ := openOutbox(t)
+		_, err := box.Add(outbox.OpSaveContact, skalinsdk.Customer{RefId: "1"})
+		assert.Error(t, err)
+		_, err = box.Add("unknown", skalinsdk.Customer{RefId: "1"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Hit location", func(t *testing.T) {
+		box, _ := openOutbox(t)
+		paris, err := time.LoadLocation("Europe/Paris")
+		if !assert.NoError(t, err) {
+			return
+		}
+		hit := newTestHit()
+		hit.Location = paris
+		entry, err := box.Add(outbox.OpHit, hit)
+		if !assert.NoError(t, err) {
+			return
+		}
+		var queued skalinsdk.HitTrack
+		if assert.NoError(t, json.Unmarshal(entry.Payload, &queued)) {
+			assert.Equal(t, "Europe/Paris", queued.TimeZone)
+		}
+
+		// a fixed zone can't be loaded back from its name
+		hit.Location = time.FixedZone("UTC+2", 2*3600)
+		_, err = box.Add(outbox.OpHit, hit)
+		assert.ErrorContains(t, err, `location "UTC+2"`)
+		hit.Location = time.FixedZone("UTC", 2*3600)
+		_, err = box.Add(outbox.OpHit, hit)
+		assert.ErrorContains(t, err, `location "UTC"`)
+		assert.Equal(t, 1, box.Len())
+	})
+}
+
+func TestClient(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	skalinApi, err := skalinsdk.New("clientId", "clientApiId", "clientApiSecret", skalinsdk.WithHTTPClient(server.Client()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	tracker, err := skalinsdk.NewTracker("clientId", skalinsdk.WithHTTPClient(server.Client()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	box, _ := openOutbox(t)
+	client := box.Client(skalinApi)
+	boxTracker := box.Tracker(tracker)
+
+	t.Run("OK", func(t *testing.T) {
+		server.InjectFault("POST /v1/customers", skalintest.Fault{Status: http.StatusServiceUnavailable})
+		server.InjectFault("/hit", skalintest.Fault{Status: http.StatusBadGateway})
+		_, err := client.SaveCustomer(skalinsdk.Customer{RefId: "1", Name: "Old name"})
+		assert.ErrorIs(t, err, outbox.ErrQueued)
+		_, err = client.SaveCustomer(skalinsdk.Customer{RefId: "1", Name: "New name"})
+		assert.ErrorIs(t, err, outbox.ErrQueued)
+		_, _, err = boxTracker.Hit(newTestHit())
+		assert.ErrorIs(t, err, outbox.ErrQueued)
+		assert.Equal(t, 2, box.Len())
+
+		// still unreachable, the replay stops at the first entry
+		report, err := box.Replay(skalinApi, tracker, outbox.ReplayOptions{})
+		assert.Error(t, err)
+		assert.Equal(t, outbox.ReplayReport{Sent: 0, Pending: 2}, report)
+		entries := box.List()
+		assert.Equal(t, 1, entries[0].Attempts)
+		assert.Equal(t, "Service Unavailable", entries[0].LastError)
+
+		server.ClearFaults()
+		report, err = box.Replay(skalinApi, tracker, outbox.ReplayOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, outbox.ReplayReport{Sent: 2, Pending: 0}, report)
+		customers := server.Entities(skalintest.Customers)
+		if assert.Len(t, customers, 1) {
+			assert.Equal(t, "New name", customers[0]["name"])
+		}
+		assert.Len(t, server.Hits(), 1)
+	})
+
+	t.Run("Replay order", func(t *testing.T) {
+		server.InjectFault("POST /v1/customers", skalintest.Fault{Status: http.StatusServiceUnavailable})
+		server.InjectFault("POST /v1/contacts", skalintest.Fault{Status: http.StatusServiceUnavailable})
+		customer := "order"
+		_, err := client.SaveCustomer(skalinsdk.Customer{RefId: customer, Name: "Old name"})
+		assert.ErrorIs(t, err, outbox.ErrQueued)
+		_, err = client.SaveContact(skalinsdk.Contact{RefId: "order-contact", Customer: &customer, LastName: "Contact"})
+		assert.ErrorIs(t, err, outbox.ErrQueued)
+		_, err = client.SaveCustomer(skalinsdk.Customer{RefId: customer, Name: "New name"})
+		assert.ErrorIs(t, err, outbox.ErrQueued)
+
+		// the customer is still replayed before its contact
+		server.ClearFaults()
+		report, err := box.Replay(skalinApi, tracker, outbox.ReplayOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, outbox.ReplayReport{Sent: 2, Pending: 0}, report)
+	})
+
+	t.Run("Hit queued as sent", func(t *testing.T) {
+		failing := skalinsdk.NewMockSkalinTracking(t)
+		failing.ExpectHit(mock.Anything).Return(nil, nil, skalinsdk.ErrCircuitOpen).Once()
+		_, _, err := box.Tracker(failing).Hit(newTestHit())
+		assert.ErrorIs(t, err, outbox.ErrQueued)
+
+		sent := failing.Calls[0].Arguments.Get(0).(skalinsdk.HitTrack)
+		if !assert.NotNil(t, sent.Ts) || !assert.NotNil(t, sent.EventID) {
+			return
+		}
+		entries := box.List()
+		var queued skalinsdk.HitTrack
+		if !assert.Len(t, entries, 1) || !assert.NoError(t, json.Unmarshal(entries[0].Payload, &queued)) {
+			return
+		}
+		assert.Equal(t, *sent.EventID, *queued.EventID)
+		assert.True(t, sent.Ts.Equal(*queued.Ts))
+		assert.NoError(t, box.Purge())
+	})
+
+	t.Run("Not queued", func(t *testing.T) {
+		// the request is rejected by Skalin, it would be rejected again on replay
+		unknownCustomer := "unknown"
+		_, err := client.SaveContact(skalinsdk.Contact{RefId: "1", Customer: &unknownCustomer})
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, outbox.ErrQueued))
+		assert.Equal(t, 0, box.Len())
+	})
+}
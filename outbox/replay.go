@@ -0,0 +1,123 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+)
+
+type ReplayOptions struct {
+	MaxRetries   int           // number of retries of an entry after the first attempt
+	RetryBackoff time.Duration // delay before the first retry, doubled on each new retry
+}
+
+var DefaultReplayOptions = ReplayOptions{
+	MaxRetries:   3,
+	RetryBackoff: time.Second,
+}
+
+type ReplayReport struct {
+	Sent    int
+	Pending int
+}
+
+// Replay sends the pending entries in order, and removes them once sent.
+// The replay stops at the first entry still failing after the retries, so the order of the writes is kept;
+// the entry keeps its number of attempts and last error, it can be removed with Remove if it can never be sent.
+// client or tracker can be nil if the outbox has no entity or no hit
+func (o *Outbox) Replay(client skalinsdk.Skalin, tracker skalinsdk.SkalinTracking, opts ReplayOptions) (ReplayReport, error) {
+	report := ReplayReport{}
+	for _, entry := range o.List() {
+		var err error
+		backoff := opts.RetryBackoff
+		attempts := entry.Attempts
+		for retries := 0; ; retries++ {
+			attempts++
+			err = send(client, tracker, entry)
+			if err == nil || retries >= opts.MaxRetries {
+				break
+			}
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err != nil {
+			report.Pending = o.Len()
+			o.mu.Lock()
+			failErr := o.write(record{Fail: &failure{Seq: entry.Seq, Attempts: attempts, Error: err.Error()}})
+			o.mu.Unlock()
+			if failErr != nil {
+				return report, failErr
+			}
+			return report, fmt.Errorf("error to replay outbox entry %v (%v): %w", entry.Seq, entry.Op, err)
+		}
+		if err := o.Remove(entry.Seq); err != nil {
+			return report, err
+		}
+		report.Sent++
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	report.Pending = len(o.entries)
+	if report.Pending == 0 {
+		return report, o.compact()
+	}
+	return report, nil
+}
+
+func send(client skalinsdk.Skalin, tracker skalinsdk.SkalinTracking, entry Entry) error {
+	if entry.Op == OpHit {
+		if tracker == nil {
+			return errors.New("no tracker to replay the hits")
+		}
+		var hit skalinsdk.HitTrack
+		if err := json.Unmarshal(entry.Payload, &hit); err != nil {
+			return err
+		}
+		_, _, err := tracker.Hit(hit)
+		if errors.Is(err, skalinsdk.ErrDuplicateHit) {
+			return nil
+		}
+		return err
+	}
+
+	if client == nil {
+		return errors.New("no client to replay the entities")
+	}
+	switch entry.Op {
+	case OpSaveContact, OpUpdateContact:
+		var contact skalinsdk.Contact
+		if err := json.Unmarshal(entry.Payload, &contact); err != nil {
+			return err
+		}
+		var err error
+		if entry.Op == OpSaveContact {
+			_, err = client.SaveContact(contact)
+		} else {
+			_, err = client.UpdateContact(contact)
+		}
+		return err
+	case OpSaveCustomer:
+		var customer skalinsdk.Customer
+		if err := json.Unmarshal(entry.Payload, &customer); err != nil {
+			return err
+		}
+		_, err := client.SaveCustomer(customer)
+		return err
+	case OpSaveAgreement, OpUpdateAgreement:
+		var agreement skalinsdk.Agreement
+		if err := json.Unmarshal(entry.Payload, &agreement); err != nil {
+			return err
+		}
+		var err error
+		if entry.Op == OpSaveAgreement {
+			_, err = client.SaveAgreement(agreement)
+		} else {
+			_, err = client.UpdateAgreement(agreement)
+		}
+		return err
+	}
+	return fmt.Errorf("unknown outbox op %q", entry.Op)
+}
@@ -0,0 +1,85 @@
+package skalinsdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryMiddleware retries the requests answered with a rate limit (429), which were not handled by Skalin.
+// The idempotent requests (GET, PUT, DELETE...) are also retried on a transport error or a server error (5xx);
+// the other ones, like the saves and the hits, could have been handled before failing, so they are not retried.
+// An open circuit breaker (ErrCircuitOpen) and a cancelled request are returned at once.
+// The delay before a retry is given by the Retry-After header of the response if any,
+// else it starts at backoff and is doubled on each retry.
+// The number of retries and the time waited because of the rate limits are set in the request
+func RetryMiddleware(maxRetries int, backoff time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (*Response, error) {
+			delay := backoff
+			for {
+				res, err := next(req)
+				if req.Retries >= maxRetries || !isRetryableResponse(req, res, err) {
+					return res, err
+				}
+				wait := delay
+				if res != nil {
+					if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+						wait = retryAfter
+					}
+				}
+				select {
+				case <-time.After(wait):
+				case <-req.Context.Done():
+					return res, err
+				}
+				if res != nil && res.StatusCode == http.StatusTooManyRequests {
+					req.RateLimitWait += wait
+				}
+				req.Retries++
+				delay *= 2
+			}
+		}
+	}
+}
+
+func isRetryableResponse(req *Request, res *Response, err error) bool {
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if res != nil && res.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if !isIdempotent(req.Method) {
+		return false
+	}
+	return err != nil || res == nil || res.StatusCode >= http.StatusInternalServerError
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
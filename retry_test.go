@@ -0,0 +1,97 @@
+package skalinsdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryMiddleware(t *testing.T) {
+	// responds with the given status codes, then 200
+	newHandler := func(statusCodes ...int) (Handler, *int) {
+		calls := 0
+		return func(req *Request) (*Response, error) {
+			calls++
+			if calls > len(statusCodes) {
+				return &Response{StatusCode: http.StatusOK}, nil
+			}
+			statusCode := statusCodes[calls-1]
+			if statusCode == 0 {
+				return nil, errors.New("connection refused")
+			}
+			return &Response{StatusCode: statusCode, Header: http.Header{"Retry-After": []string{"0"}}}, nil
+		}, &calls
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		handler, calls := newHandler(http.StatusTooManyRequests, 0, http.StatusServiceUnavailable)
+		req := &Request{Context: context.Background(), Method: http.MethodGet}
+		res, err := RetryMiddleware(3, time.Millisecond)(handler)(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, 4, *calls)
+		assert.Equal(t, 3, req.Retries)
+	})
+
+	t.Run("Not retryable", func(t *testing.T) {
+		handler, calls := newHandler(http.StatusBadRequest)
+		req := &Request{Context: context.Background(), Method: http.MethodGet}
+		res, err := RetryMiddleware(3, time.Millisecond)(handler)(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, 0, req.Retries)
+	})
+
+	t.Run("Not idempotent", func(t *testing.T) {
+		// a save may have been handled before the error, only the rate limit is retried
+		handler, calls := newHandler(http.StatusTooManyRequests, http.StatusServiceUnavailable)
+		req := &Request{Context: context.Background(), Method: http.MethodPost}
+		res, err := RetryMiddleware(3, time.Millisecond)(handler)(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.Equal(t, 2, *calls)
+
+		handler, calls = newHandler(0)
+		_, err = RetryMiddleware(3, time.Millisecond)(handler)(&Request{Context: context.Background(), Method: http.MethodPost})
+		assert.Error(t, err)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("Circuit open", func(t *testing.T) {
+		calls := 0
+		handler := func(req *Request) (*Response, error) {
+			calls++
+			return nil, ErrCircuitOpen
+		}
+		_, err := RetryMiddleware(3, time.Millisecond)(handler)(&Request{Context: context.Background(), Method: http.MethodGet})
+		assert.True(t, errors.Is(err, ErrCircuitOpen))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		handler, calls := newHandler(0, 0, 0)
+		req := &Request{Context: context.Background(), Method: http.MethodGet}
+		_, err := RetryMiddleware(1, time.Millisecond)(handler)(req)
+		assert.Error(t, err)
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, 1, req.Retries)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	wait, ok := parseRetryAfter("2")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, wait)
+
+	wait, ok = parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
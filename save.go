@@ -1,6 +1,7 @@
 package skalinsdk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -71,6 +72,9 @@ func buildQueryParamsFromGetParams(params *GetParams) *url.Values {
 }
 
 func save[T EntitiesGeneric](s *skalinAPI, path string, entity T) (*T, error) {
+	if err := s.checkEntity(entity); err != nil {
+		return nil, err
+	}
 	url := BuildUrl(path)
 	jsonEntity, err := json.Marshal(entity)
 	if err != nil {
@@ -93,10 +97,16 @@ func save[T EntitiesGeneric](s *skalinAPI, path string, entity T) (*T, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error to unmarshal entity for save [%v] response: %w", path, err)
 	}
+	if err := s.readEntity(&jsonResp.Data); err != nil {
+		return nil, fmt.Errorf("error to read entity for save [%v] response: %w", path, err)
+	}
 	return &jsonResp.Data, nil
 }
 
 func update[T EntitiesGeneric](s *skalinAPI, path string, entity T) error {
+	if err := s.checkEntity(entity); err != nil {
+		return err
+	}
 	url := BuildUrl(path)
 	jsonEntity, err := json.Marshal(entity)
 	if err != nil {
@@ -116,9 +126,9 @@ func update[T EntitiesGeneric](s *skalinAPI, path string, entity T) error {
 	return err
 }
 
-func getEntitiesWithMetadata[T EntitySlice[V], V EntitiesGeneric, U PaginationMetadata](s *skalinAPI, path string, queryParams *url.Values) (*GenericResponse[T, V, U], error) {
+func getEntitiesWithMetadata[T EntitySlice[V], V EntitiesGeneric, U PaginationMetadata](ctx context.Context, s *skalinAPI, path string, queryParams *url.Values) (*GenericResponse[T, V, U], error) {
 	url := BuildUrl(path)
-	_, bodyResp, err := s.api.GetData(
+	_, bodyResp, err := s.withContext(ctx).GetData(
 		url,
 		jsonContentType,
 		nil,
@@ -135,20 +145,51 @@ func getEntitiesWithMetadata[T EntitySlice[V], V EntitiesGeneric, U PaginationMe
 	if err != nil {
 		return nil, fmt.Errorf("error to unmarshal entity for get [%v] response: %w", path, err)
 	}
+	for i := range jsonResp.Data {
+		if err := s.readEntity(&jsonResp.Data[i]); err != nil {
+			return nil, fmt.Errorf("error to read entity for get [%v] response: %w", path, err)
+		}
+	}
 	return &jsonResp, nil
 }
 
 func getEntities[T EntitySlice[V], V EntitiesGeneric](s *skalinAPI, path string, queryParams *url.Values) (T, error) {
 	data := make(T, 0)
+	err := walkEntities[T](s, path, queryParams, func(page T) error {
+		data = append(data, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// walkEntities calls fn with the entities of each page, so the entities are never all loaded in memory.
+// It stops at the first error returned by fn
+func walkEntities[T EntitySlice[V], V EntitiesGeneric](s *skalinAPI, path string, queryParams *url.Values, fn func(T) error) (err error) {
 	if queryParams == nil {
 		queryParams = &url.Values{}
 	}
+	pages := 0
+	ctx, endList := s.startList(context.Background(), path)
+	defer func() { endList(pages, err) }()
 	for {
-		jsonResp, err := getEntitiesWithMetadata[T](s, path, queryParams)
+		requestedPage, err := strconv.Atoi(queryParams.Get("page"))
 		if err != nil {
-			return nil, err
+			requestedPage = 1
+		}
+		pageCtx, endPage := s.startPage(ctx, path, requestedPage)
+		jsonResp, err := getEntitiesWithMetadata[T](pageCtx, s, path, queryParams)
+		if err != nil {
+			endPage(0, err)
+			return err
+		}
+		endPage(len(jsonResp.Data), nil)
+		pages++
+		if err := fn(jsonResp.Data); err != nil {
+			return err
 		}
-		data = append(data, jsonResp.Data...)
 		page := jsonResp.Metadata.Pagination.Page
 		queryParams.Set("page", strconv.Itoa(page+1))
 		// need to continue to get data until the total is reached
@@ -156,7 +197,7 @@ func getEntities[T EntitySlice[V], V EntitiesGeneric](s *skalinAPI, path string,
 			break
 		}
 	}
-	return data, nil
+	return nil
 }
 
 func getEntity[T Customer | Contact | Agreement | Tag](s *skalinAPI, path string, queryParams *url.Values) (*T, error) {
@@ -178,5 +219,71 @@ func getEntity[T Customer | Contact | Agreement | Tag](s *skalinAPI, path string
 	if err != nil {
 		return nil, fmt.Errorf("error to unmarshal entity for get [%v] response: %w", path, err)
 	}
+	if err := s.readEntity(&jsonResp.Data); err != nil {
+		return nil, fmt.Errorf("error to read entity for get [%v] response: %w", path, err)
+	}
 	return &jsonResp.Data, nil
 }
+
+// accountCustomAttributes returns the attributes whose keys are set with WithCustomAttributes, or nil
+func (s *skalinAPI) accountCustomAttributes(attributes CustomAttributes) CustomAttributes {
+	var kept CustomAttributes
+	for key, value := range attributes {
+		if !s.customAttributes[key] {
+			continue
+		}
+		if kept == nil {
+			kept = make(CustomAttributes)
+		}
+		kept[key] = value
+	}
+	return kept
+}
+
+func (s *skalinAPI) accountCurrency() Currency {
+	if s.currency == "" {
+		return CurrencyEUR
+	}
+	return s.currency
+}
+
+// checkEntity rejects an entity before it is sent: an unknown enum value, or an amount in another currency than the account
+func (s *skalinAPI) checkEntity(entity interface{}) error {
+	if err := s.enums.check(entity); err != nil {
+		return err
+	}
+	if agreement, ok := entity.(Agreement); ok {
+		for _, amount := range []*Money{agreement.Mrr, agreement.Fee} {
+			if amount == nil {
+				continue
+			}
+			if _, err := amount.In(s.accountCurrency()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readEntity sets the currency of the account on the amounts read from Skalin,
+// and only keeps the custom attributes of the account
+func (s *skalinAPI) readEntity(entity interface{}) error {
+	switch e := entity.(type) {
+	case *Customer:
+		e.CustomAttributes = s.accountCustomAttributes(e.CustomAttributes)
+	case *Contact:
+		e.CustomAttributes = s.accountCustomAttributes(e.CustomAttributes)
+	case *Agreement:
+		for _, amount := range []*Money{e.Mrr, e.Fee} {
+			if amount == nil {
+				continue
+			}
+			money, err := amount.In(s.accountCurrency())
+			if err != nil {
+				return err
+			}
+			*amount = money
+		}
+	}
+	return nil
+}
@@ -8,50 +8,77 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+//go:generate go run ./internal/mockgen
+
 type Skalin interface {
 	GetContacts(*GetParams) ([]Contact, error)
+	WalkContacts(*GetParams, func([]Contact) error) error
 	SaveContact(Contact) (*Contact, error)
 	UpdateContact(Contact) (*Contact, error)
 	CreateContactForCustomer(Contact, string) (*Contact, error)
 	DeleteContact(Contact) error
 
 	GetCustomers(*GetParams) ([]Customer, error)
+	WalkCustomers(*GetParams, func([]Customer) error) error
 	SaveCustomer(Customer) (*Customer, error)
+	UpdateCustomer(Customer) (*Customer, error)
+	DeleteCustomer(Customer) error
 
 	GetAgreements(*GetParams) ([]Agreement, error)
+	WalkAgreements(*GetParams, func([]Agreement) error) error
 	SaveAgreement(Agreement) (*Agreement, error)
 	UpdateAgreement(Agreement) (*Agreement, error)
 	CreateAgreementForCustomer(Agreement, string) (*Agreement, error)
 	DeleteAgreement(Agreement) error
 
 	GetTags(*GetParams) ([]Tag, error)
+	WalkTags(*GetParams, func([]Tag) error) error
 	GetTagByID(id string) (*Tag, error)
 
 	SetLogger(logger logrus.FieldLogger)
 }
 
 type SkalinTracking interface {
-	Hit(HitTrack) error
+	Hit(HitTrack) (*http.Response, []byte, error)
+	HitBatch([]HitTrack) (HitResults, error)
+	HitBatchWithOptions([]HitTrack, HitBatchOptions) (HitResults, error)
 }
 
 type skalinAPI struct {
-	api API
+	api           API
+	listObservers []ListObserver
+	enums         enumValidator
+	currency      Currency // EUR if empty
+	// keys of the custom attributes read, see WithCustomAttributes
+	customAttributes map[string]bool
 }
 
 type skalinTracker struct {
-	api API
+	api          API
+	dedup        *hitDedup
+	hitObservers []HitObserver
 }
 
+var _ SkalinTracking = skalinTracker{}
+
 func (s *skalinAPI) SetLogger(logger logrus.FieldLogger) {
 	s.api.SetLogger(logger)
 }
 
-func New(clientId, clientApiId, clientApiSecret string) (Skalin, error) {
+func New(clientId, clientApiId, clientApiSecret string, opts ...Option) (Skalin, error) {
 	//format string with parameter
 	body := fmt.Sprintf(`{"client_id": "%s", "client_secret": "%s", "grant_type": "client_credentials", "audience":"https://api.skalin.io/"}`, clientApiId, clientApiSecret)
 	skalinApi := new(SkalinAPI)
+	for _, opt := range opts {
+		opt(skalinApi)
+	}
+	skalinApi.setupLogger()
 	response, responseBytes, err := skalinApi.PostData(SKALIN_AUTH_URL, "application/json", nil, []byte(body), nil, http.StatusOK)
 	if err != nil {
+		// response is nil if the auth endpoint was not reached
+		if response == nil {
+			return nil, fmt.Errorf("error=%s", err)
+		}
 		return nil, fmt.Errorf("error=%s; httpCode=%d", err, response.StatusCode)
 	}
 	var data map[string]interface{}
@@ -59,18 +86,36 @@ func New(clientId, clientApiId, clientApiSecret string) (Skalin, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error=%s; httpCode=%d", err, response.StatusCode)
 	}
-
-	logrus.Infof("%s", data)
+	accessToken, ok := data["access_token"].(string)
+	if !ok {
+		return nil, fmt.Errorf("error=no access_token in auth response; httpCode=%d", response.StatusCode)
+	}
+	skalinApi.log().Log(LevelDebug, "authenticated to skalin API", Fields{"expiresIn": data["expires_in"]})
 	skalin := &skalinAPI{
-		api: skalinApi.WithClientID(clientId).WithToken(data["access_token"].(string)),
+		api:           skalinApi.WithClientID(clientId).WithToken(accessToken),
+		listObservers: skalinApi.listObservers,
+		enums:         newEnumValidator(skalinApi.enumMode, skalinApi.customerStages),
+		currency:      skalinApi.currency,
+	}
+	if len(skalinApi.customAttributes) > 0 {
+		skalin.customAttributes = make(map[string]bool, len(skalinApi.customAttributes))
+		for _, key := range skalinApi.customAttributes {
+			skalin.customAttributes[key] = true
+		}
 	}
-	// set default logger (but can be replace by another one)
-	skalin.SetLogger(Log)
 	return skalin, nil
 }
 
-func NewTracker(clientId string) (skalinTracker, error) {
-	skalinApi := new(SkalinAPI).WithClientID(clientId)
-	skalinApi.SetLogger(Log)
-	return skalinTracker{skalinApi}, nil
+func NewTracker(clientId string, opts ...Option) (skalinTracker, error) {
+	skalinApi := new(SkalinAPI)
+	for _, opt := range opts {
+		opt(skalinApi)
+	}
+	skalinApi.setupLogger()
+	skalinApi.WithClientID(clientId)
+	return skalinTracker{
+		api:          skalinApi,
+		dedup:        newHitDedup(DefaultHitDedupWindow),
+		hitObservers: skalinApi.hitObservers,
+	}, nil
 }
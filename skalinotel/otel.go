@@ -0,0 +1,213 @@
+// Package skalinotel instruments the Skalin client and tracker with OpenTelemetry traces and metrics.
+//
+//	instrumentation, err := skalinotel.New()
+//	if err != nil {
+//		panic(err)
+//	}
+//	skalinApi, err := skalinsdk.New(clientID, clientApiID, clientApiSecret, instrumentation.Option())
+//
+// The middleware records a span per call to Skalin, with the method, the route template, the status code
+// and the number of retries done by skalinsdk.RetryMiddleware (which must be added after the instrumentation).
+// The Get* methods record a span per list with a child span per page
+package skalinotel
+
+import (
+	"context"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/karnott/skalin-sdk/skalinotel"
+
+const (
+	methodKey     = attribute.Key("http.request.method")
+	routeKey      = attribute.Key("http.route")
+	statusCodeKey = attribute.Key("http.response.status_code")
+	retriesKey    = attribute.Key("skalin.retries")
+	pathKey       = attribute.Key("skalin.path")
+	pageKey       = attribute.Key("skalin.page")
+	pagesKey      = attribute.Key("skalin.pages")
+	countKey      = attribute.Key("skalin.count")
+	hitStatusKey  = attribute.Key("skalin.hit.status")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// Option configures the instrumentation
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider (the global one by default)
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider (the global one by default)
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// Instrumentation is a middleware, a list observer and a hit observer for the Skalin client and tracker
+type Instrumentation struct {
+	tracer          trace.Tracer
+	requestDuration metric.Float64Histogram
+	requests        metric.Int64Counter
+	requestErrors   metric.Int64Counter
+	hits            metric.Int64Counter
+	hitQueueDepth   metric.Int64UpDownCounter
+}
+
+var (
+	_ skalinsdk.ListObserver = (*Instrumentation)(nil)
+	_ skalinsdk.HitObserver  = (*Instrumentation)(nil)
+)
+
+func New(opts ...Option) (*Instrumentation, error) {
+	c := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	meter := c.meterProvider.Meter(instrumentationName)
+	i := &Instrumentation{tracer: c.tracerProvider.Tracer(instrumentationName)}
+	var err error
+	i.requestDuration, err = meter.Float64Histogram(
+		"skalin.client.request.duration",
+		metric.WithDescription("Duration of the calls to Skalin, retries included"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	i.requests, err = meter.Int64Counter(
+		"skalin.client.requests",
+		metric.WithDescription("Number of calls to Skalin"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	i.requestErrors, err = meter.Int64Counter(
+		"skalin.client.request.errors",
+		metric.WithDescription("Number of calls to Skalin which failed"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	i.hits, err = meter.Int64Counter(
+		"skalin.tracker.hits",
+		metric.WithDescription("Number of hits handled by the tracker, by status"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	i.hitQueueDepth, err = meter.Int64UpDownCounter(
+		"skalin.tracker.hit_queue.depth",
+		metric.WithDescription("Number of hits waiting to be sent by the tracker"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// Option adds the middleware and the observers of the instrumentation to a client or a tracker
+func (i *Instrumentation) Option() skalinsdk.Option {
+	opts := []skalinsdk.Option{
+		skalinsdk.WithMiddlewares(i.Middleware()),
+		skalinsdk.WithListObserver(i),
+		skalinsdk.WithHitObserver(i),
+	}
+	return func(a *skalinsdk.SkalinAPI) {
+		for _, opt := range opts {
+			opt(a)
+		}
+	}
+}
+
+// Middleware records a span and the metrics of each call to Skalin
+func (i *Instrumentation) Middleware() skalinsdk.Middleware {
+	return func(next skalinsdk.Handler) skalinsdk.Handler {
+		return func(req *skalinsdk.Request) (*skalinsdk.Response, error) {
+			attrs := []attribute.KeyValue{methodKey.String(req.Method), routeKey.String(req.Route)}
+			ctx, span := i.tracer.Start(req.Context, "skalin "+req.Method+" "+req.Route,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+			req.Context = ctx
+
+			start := time.Now()
+			res, err := next(req)
+			duration := time.Since(start)
+
+			if res != nil {
+				attrs = append(attrs, statusCodeKey.Int(res.StatusCode))
+			}
+			span.SetAttributes(attrs...)
+			span.SetAttributes(retriesKey.Int(req.Retries))
+			failed := err != nil || res == nil || res.StatusCode != req.ExpectedStatusCode
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			} else if failed {
+				span.SetStatus(codes.Error, "unexpected status code")
+			}
+
+			measureOpt := metric.WithAttributes(attrs...)
+			i.requestDuration.Record(ctx, duration.Seconds(), measureOpt)
+			i.requests.Add(ctx, 1, measureOpt)
+			if failed {
+				i.requestErrors.Add(ctx, 1, measureOpt)
+			}
+			return res, err
+		}
+	}
+}
+
+func (i *Instrumentation) StartList(ctx context.Context, path string) (context.Context, func(pages int, err error)) {
+	ctx, span := i.tracer.Start(ctx, "skalin list "+path, trace.WithAttributes(pathKey.String(path)))
+	return ctx, func(pages int, err error) {
+		span.SetAttributes(pagesKey.Int(pages))
+		endSpan(span, err)
+	}
+}
+
+func (i *Instrumentation) StartPage(ctx context.Context, path string, page int) (context.Context, func(count int, err error)) {
+	ctx, span := i.tracer.Start(ctx, "skalin page "+path, trace.WithAttributes(pathKey.String(path), pageKey.Int(page)))
+	return ctx, func(count int, err error) {
+		span.SetAttributes(countKey.Int(count))
+		endSpan(span, err)
+	}
+}
+
+func (i *Instrumentation) HitQueued(count int) {
+	i.hitQueueDepth.Add(context.Background(), int64(count))
+}
+
+func (i *Instrumentation) HitDone(status skalinsdk.HitStatus, attempts int) {
+	ctx := context.Background()
+	i.hitQueueDepth.Add(ctx, -1)
+	i.hits.Add(ctx, 1, metric.WithAttributes(hitStatusKey.String(string(status))))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
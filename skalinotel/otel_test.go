@@ -0,0 +1,175 @@
+package skalinotel_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/skalinotel"
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newInstrumentation(t *testing.T) (*skalinotel.Instrumentation, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	instrumentation, err := skalinotel.New(
+		skalinotel.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		skalinotel.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return instrumentation, spans, reader
+}
+
+func attributeValue(attrs []attribute.KeyValue, key string) attribute.Value {
+	for _, attr := range attrs {
+		if string(attr.Key) == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func findMetric(t *testing.T, reader *sdkmetric.ManualReader, name string) metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("%v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m.Data
+			}
+		}
+	}
+	return nil
+}
+
+func TestTraces(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	for _, refID := range []string{"1", "2", "3"} {
+		if _, err := server.Seed(skalintest.Contacts, skalintest.Entity{"refId": refID}); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	instrumentation, spans, reader := newInstrumentation(t)
+	skalinApi, err := skalinsdk.New("clientId", "clientApiId", "clientApiSecret",
+		skalinsdk.WithHTTPClient(server.Client()),
+		instrumentation.Option(),
+		skalinsdk.WithMiddlewares(skalinsdk.RetryMiddleware(1, time.Millisecond)),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		before := len(spans.Ended())
+		server.InjectFault("GET /v1/contacts", skalintest.Fault{Status: http.StatusServiceUnavailable, Times: 1})
+		defer server.ClearFaults()
+		size := 2
+		contacts, err := skalinApi.GetContacts(&skalinsdk.GetParams{Size: &size})
+		if !assert.NoError(t, err) || !assert.Len(t, contacts, 3) {
+			return
+		}
+
+		ended := spans.Ended()[before:]
+		if !assert.Len(t, ended, 5) {
+			return
+		}
+		// spans are ended from the innermost to the outermost
+		firstCall, firstPage, secondCall, secondPage, list := ended[0], ended[1], ended[2], ended[3], ended[4]
+		assert.Equal(t, "skalin list /contacts", list.Name())
+		assert.Equal(t, int64(2), attributeValue(list.Attributes(), "skalin.pages").AsInt64())
+
+		assert.Equal(t, list.SpanContext().SpanID(), firstPage.Parent().SpanID())
+		assert.Equal(t, int64(1), attributeValue(firstPage.Attributes(), "skalin.page").AsInt64())
+		assert.Equal(t, int64(2), attributeValue(firstPage.Attributes(), "skalin.count").AsInt64())
+		assert.Equal(t, int64(2), attributeValue(secondPage.Attributes(), "skalin.page").AsInt64())
+		assert.Equal(t, int64(1), attributeValue(secondPage.Attributes(), "skalin.count").AsInt64())
+
+		assert.Equal(t, "skalin GET /contacts", firstCall.Name())
+		assert.Equal(t, firstPage.SpanContext().SpanID(), firstCall.Parent().SpanID())
+		assert.Equal(t, "/contacts", attributeValue(firstCall.Attributes(), "http.route").AsString())
+		assert.Equal(t, int64(http.StatusOK), attributeValue(firstCall.Attributes(), "http.response.status_code").AsInt64())
+		assert.Equal(t, int64(1), attributeValue(firstCall.Attributes(), "skalin.retries").AsInt64())
+		assert.Equal(t, int64(0), attributeValue(secondCall.Attributes(), "skalin.retries").AsInt64())
+		assert.Equal(t, secondPage.SpanContext().SpanID(), secondCall.Parent().SpanID())
+
+		requests, ok := findMetric(t, reader, "skalin.client.requests").(metricdata.Sum[int64])
+		if assert.True(t, ok) {
+			total := int64(0)
+			for _, point := range requests.DataPoints {
+				total += point.Value
+			}
+			// auth and 2 pages
+			assert.Equal(t, int64(3), total)
+		}
+		_, ok = findMetric(t, reader, "skalin.client.request.duration").(metricdata.Histogram[float64])
+		assert.True(t, ok)
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		before := len(spans.Ended())
+		_, err := skalinApi.UpdateContact(skalinsdk.Contact{Id: "unknown"})
+		assert.Error(t, err)
+		ended := spans.Ended()[before:]
+		if !assert.Len(t, ended, 1) {
+			return
+		}
+		assert.Equal(t, "skalin PATCH /contacts/{id}", ended[0].Name())
+		assert.Equal(t, codes.Error, ended[0].Status().Code)
+
+		errors, ok := findMetric(t, reader, "skalin.client.request.errors").(metricdata.Sum[int64])
+		if assert.True(t, ok) && assert.Len(t, errors.DataPoints, 1) {
+			assert.Equal(t, int64(1), errors.DataPoints[0].Value)
+		}
+	})
+}
+
+func TestHitMetrics(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	instrumentation, _, reader := newInstrumentation(t)
+	tracker, err := skalinsdk.NewTracker("clientId", skalinsdk.WithHTTPClient(server.Client()), instrumentation.Option())
+	if !assert.NoError(t, err) {
+		return
+	}
+	identityID := "identity"
+	ts := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	hit := skalinsdk.HitTrack{
+		Action:    skalinsdk.HitActionEvent,
+		VisitorID: "1111111111111111",
+		VisitID:   "1234567890123456",
+		Identity:  skalinsdk.HitIdentity{ID: &identityID},
+		Event:     &skalinsdk.HitEvent{Name: "event", EventName: "event"},
+		Ts:        &ts,
+	}
+	_, err = tracker.HitBatch([]skalinsdk.HitTrack{hit, hit})
+	assert.NoError(t, err)
+
+	depth, ok := findMetric(t, reader, "skalin.tracker.hit_queue.depth").(metricdata.Sum[int64])
+	if assert.True(t, ok) && assert.Len(t, depth.DataPoints, 1) {
+		assert.Equal(t, int64(0), depth.DataPoints[0].Value)
+	}
+	hits, ok := findMetric(t, reader, "skalin.tracker.hits").(metricdata.Sum[int64])
+	if assert.True(t, ok) {
+		byStatus := make(map[string]int64)
+		for _, point := range hits.DataPoints {
+			status, _ := point.Attributes.Value("skalin.hit.status")
+			byStatus[status.AsString()] = point.Value
+		}
+		// the same hit is only sent once
+		assert.Equal(t, map[string]int64{"sent": 1, "dropped": 1}, byStatus)
+	}
+}
@@ -0,0 +1,165 @@
+// Package skalinprom exposes Prometheus metrics for the Skalin client and tracker.
+// The collector is never registered globally, the caller registers it on its registry:
+//
+//	collector := skalinprom.NewCollector()
+//	registry.MustRegister(collector)
+//	skalinApi, err := skalinsdk.New(clientID, clientApiID, clientApiSecret, collector.Option())
+//	tracker, err := skalinsdk.NewTracker(clientID, collector.Option())
+package skalinprom
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "skalin"
+	hitRoute  = "/hit"
+)
+
+// Collector is a prometheus.Collector, and a middleware, a list observer and a hit observer for the Skalin client and tracker
+type Collector struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	retries         *prometheus.CounterVec
+	rateLimitWait   *prometheus.CounterVec
+	pages           *prometheus.CounterVec
+	hits            *prometheus.CounterVec
+}
+
+var (
+	_ prometheus.Collector   = (*Collector)(nil)
+	_ skalinsdk.ListObserver = (*Collector)(nil)
+	_ skalinsdk.HitObserver  = (*Collector)(nil)
+)
+
+func NewCollector() *Collector {
+	c := &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "requests_total",
+			Help:      "Number of calls to Skalin by route and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the calls to Skalin by route and status code, retries included.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "retries_total",
+			Help:      "Number of retries of the calls to Skalin by route.",
+		}, []string{"method", "route"}),
+		rateLimitWait: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "rate_limit_wait_seconds_total",
+			Help:      "Time waited because of the rate limits of Skalin by route.",
+		}, []string{"method", "route"}),
+		pages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "pages_total",
+			Help:      "Number of pages fetched by the Get* methods by entity path.",
+		}, []string{"path"}),
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "tracker",
+			Name:      "hits_total",
+			Help:      "Number of hits handled by the tracker by status (sent, dropped or failed).",
+		}, []string{"status"}),
+	}
+	// the hits are reported even before the first one, so the rates can be computed
+	for _, status := range []skalinsdk.HitStatus{skalinsdk.HitSent, skalinsdk.HitDropped, skalinsdk.HitFailed} {
+		c.hits.WithLabelValues(string(status))
+	}
+	return c
+}
+
+func (c *Collector) vecs() []prometheus.Collector {
+	return []prometheus.Collector{c.requests, c.requestDuration, c.retries, c.rateLimitWait, c.pages, c.hits}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, vec := range c.vecs() {
+		vec.Describe(ch)
+	}
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, vec := range c.vecs() {
+		vec.Collect(ch)
+	}
+}
+
+// Option adds the middleware and the observers of the collector to a client or a tracker
+func (c *Collector) Option() skalinsdk.Option {
+	opts := []skalinsdk.Option{
+		skalinsdk.WithMiddlewares(c.Middleware()),
+		skalinsdk.WithListObserver(c),
+		skalinsdk.WithHitObserver(c),
+	}
+	return func(a *skalinsdk.SkalinAPI) {
+		for _, opt := range opts {
+			opt(a)
+		}
+	}
+}
+
+// Middleware measures the calls to Skalin.
+// The retries and rate limit waits are the ones of skalinsdk.RetryMiddleware, which must be added after the collector
+func (c *Collector) Middleware() skalinsdk.Middleware {
+	return func(next skalinsdk.Handler) skalinsdk.Handler {
+		return func(req *skalinsdk.Request) (*skalinsdk.Response, error) {
+			start := time.Now()
+			res, err := next(req)
+			duration := time.Since(start)
+
+			// status is "error" when Skalin was not reached
+			status := "error"
+			if err == nil && res != nil {
+				status = strconv.Itoa(res.StatusCode)
+			}
+			c.requests.WithLabelValues(req.Method, req.Route, status).Inc()
+			c.requestDuration.WithLabelValues(req.Method, req.Route, status).Observe(duration.Seconds())
+			if req.Retries > 0 {
+				c.retries.WithLabelValues(req.Method, req.Route).Add(float64(req.Retries))
+			}
+			if req.RateLimitWait > 0 {
+				c.rateLimitWait.WithLabelValues(req.Method, req.Route).Add(req.RateLimitWait.Seconds())
+			}
+			return res, err
+		}
+	}
+}
+
+func (c *Collector) StartList(ctx context.Context, path string) (context.Context, func(pages int, err error)) {
+	return ctx, func(int, error) {}
+}
+
+func (c *Collector) StartPage(ctx context.Context, path string, page int) (context.Context, func(count int, err error)) {
+	return ctx, func(count int, err error) {
+		if err == nil {
+			c.pages.WithLabelValues(path).Inc()
+		}
+	}
+}
+
+func (c *Collector) HitQueued(count int) {}
+
+func (c *Collector) HitDone(status skalinsdk.HitStatus, attempts int) {
+	c.hits.WithLabelValues(string(status)).Inc()
+	// the hits retried by HitBatch are sent again, without RetryMiddleware
+	if attempts > 1 {
+		c.retries.WithLabelValues(http.MethodPost, hitRoute).Add(float64(attempts - 1))
+	}
+}
@@ -0,0 +1,88 @@
+package skalinprom_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/skalinprom"
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	for _, refID := range []string{"1", "2", "3"} {
+		_, err := server.Seed(skalintest.Tags, skalintest.Entity{"refId": refID})
+		assert.NoError(t, err)
+	}
+	collector := skalinprom.NewCollector()
+	registry := prometheus.NewPedanticRegistry()
+	if !assert.NoError(t, registry.Register(collector)) {
+		return
+	}
+	skalinApi, err := skalinsdk.New("clientId", "clientApiId", "clientApiSecret",
+		skalinsdk.WithHTTPClient(server.Client()),
+		collector.Option(),
+		skalinsdk.WithMiddlewares(skalinsdk.RetryMiddleware(1, time.Millisecond)),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	tracker, err := skalinsdk.NewTracker("clientId", skalinsdk.WithHTTPClient(server.Client()), collector.Option())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	server.InjectFault("GET /v1/tags", skalintest.Fault{Status: http.StatusInternalServerError, Times: 1})
+	size := 2
+	_, err = skalinApi.GetTags(&skalinsdk.GetParams{Size: &size})
+	assert.NoError(t, err)
+	_, err = skalinApi.GetTagByID("unknown")
+	assert.Error(t, err)
+
+	identityID := "identity"
+	hit := skalinsdk.HitTrack{
+		Action:    skalinsdk.HitActionEvent,
+		VisitorID: "1111111111111111",
+		VisitID:   "1234567890123456",
+		Identity:  skalinsdk.HitIdentity{ID: &identityID},
+		Event:     &skalinsdk.HitEvent{Name: "event", EventName: "event"},
+	}
+	_, _, err = tracker.Hit(hit)
+	assert.NoError(t, err)
+	_, _, err = tracker.Hit(skalinsdk.HitTrack{})
+	assert.Error(t, err)
+
+	expected := `
+# HELP skalin_client_pages_total Number of pages fetched by the Get* methods by entity path.
+# TYPE skalin_client_pages_total counter
+skalin_client_pages_total{path="/tags"} 2
+# HELP skalin_client_requests_total Number of calls to Skalin by route and status code.
+# TYPE skalin_client_requests_total counter
+skalin_client_requests_total{method="GET",route="/tags",status="200"} 2
+skalin_client_requests_total{method="GET",route="/tags/{id}",status="404"} 1
+skalin_client_requests_total{method="POST",route="/hit",status="200"} 1
+skalin_client_requests_total{method="POST",route="/oauth/token",status="200"} 1
+# HELP skalin_client_retries_total Number of retries of the calls to Skalin by route.
+# TYPE skalin_client_retries_total counter
+skalin_client_retries_total{method="GET",route="/tags"} 1
+# HELP skalin_tracker_hits_total Number of hits handled by the tracker by status (sent, dropped or failed).
+# TYPE skalin_tracker_hits_total counter
+skalin_tracker_hits_total{status="dropped"} 1
+skalin_tracker_hits_total{status="failed"} 0
+skalin_tracker_hits_total{status="sent"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"skalin_client_pages_total",
+		"skalin_client_requests_total",
+		"skalin_client_retries_total",
+		"skalin_tracker_hits_total",
+	))
+	assert.Equal(t, 4, testutil.CollectAndCount(collector, "skalin_client_request_duration_seconds"))
+}
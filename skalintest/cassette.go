@@ -0,0 +1,285 @@
+package skalintest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+type Mode string
+
+const (
+	ModeReplay Mode = "replay"
+	ModeRecord Mode = "record"
+
+	// CassetteModeEnv is the env var used by NewCassette to choose the mode (replay by default)
+	CassetteModeEnv = "SKALIN_CASSETTE_MODE"
+
+	redacted = "REDACTED"
+)
+
+// secrets replaced in the recorded requests and responses
+var (
+	redactedQueryParams = []string{"clientId", "client_id"}
+	redactedJSONFields  = []string{"access_token", "client_secret", "client_id"}
+)
+
+// Interaction is a request and its response, as saved in a cassette file
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper which records the traffic with Skalin in a cassette file,
+// or replays a cassette file without network access.
+// In record mode, the token, the client secret and the client id are redacted before being saved
+type Recorder struct {
+	mode Mode
+	path string
+	base http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+// NewRecorder creates a recorder for the cassette file at path.
+// In replay mode, the file is loaded; in record mode, the requests are sent with base
+// (http.DefaultTransport if nil) and Save writes the file
+func NewRecorder(path string, mode Mode, base http.RoundTripper) (*Recorder, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	r := &Recorder{mode: mode, path: path, base: base}
+	switch mode {
+	case ModeRecord:
+		return r, nil
+	case ModeReplay:
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error to read cassette %v: %w", path, err)
+		}
+		if err := json.Unmarshal(b, &r.interactions); err != nil {
+			return nil, fmt.Errorf("error to unmarshal cassette %v: %w", path, err)
+		}
+		r.replayed = make([]bool, len(r.interactions))
+		return r, nil
+	}
+	return nil, fmt.Errorf("unknown cassette mode %q", mode)
+}
+
+// NewCassette returns an HTTP client using the cassette testdata/cassettes/<name>.json.
+// The mode is read from the SKALIN_CASSETTE_MODE env var. In record mode, the cassette is saved at the end of the test;
+// in replay mode, the test fails if a request was not recorded or if a recorded request was not replayed
+func NewCassette(t testing.TB, name string) *http.Client {
+	t.Helper()
+	mode := ModeReplay
+	if Mode(os.Getenv(CassetteModeEnv)) == ModeRecord {
+		mode = ModeRecord
+	}
+	recorder, err := NewRecorder(filepath.Join("testdata", "cassettes", name+".json"), mode, nil)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	t.Cleanup(func() {
+		if mode == ModeRecord {
+			if err := recorder.Save(); err != nil {
+				t.Errorf("%v", err)
+			}
+			return
+		}
+		for _, err := range recorder.Errors() {
+			t.Errorf("%v", err)
+		}
+	})
+	return &http.Client{Transport: recorder}
+}
+
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recordedRequest, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, recordedRequest)
+	}
+
+	res, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, Interaction{
+		Request: recordedRequest,
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     http.Header{"Content-Type": res.Header.Values("Content-Type")},
+			Body:       redactJSON(string(body)),
+		},
+	})
+	return res, nil
+}
+
+// replay answers with the first recorded interaction matching the request which was not replayed yet
+func (r *Recorder) replay(req *http.Request, recordedRequest RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if r.replayed[i] || !interaction.Request.matches(recordedRequest) {
+			continue
+		}
+		r.replayed[i] = true
+		header := interaction.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewBufferString(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	err := fmt.Errorf("skalintest: no recorded interaction in %v for %v %v", r.path, recordedRequest.Method, recordedRequest.URL)
+	r.interactions = append(r.interactions, Interaction{Request: recordedRequest})
+	r.replayed = append(r.replayed, false)
+	return nil, err
+}
+
+// Errors returns the replay errors: requests without recorded interaction, and recorded interactions not replayed
+func (r *Recorder) Errors() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	errs := make([]error, 0)
+	for i, interaction := range r.interactions {
+		if r.replayed[i] {
+			continue
+		}
+		if interaction.Response.StatusCode == 0 {
+			errs = append(errs, fmt.Errorf("skalintest: unmatched request %v %v", interaction.Request.Method, interaction.Request.URL))
+			continue
+		}
+		errs = append(errs, fmt.Errorf("skalintest: recorded interaction %v %v was not replayed", interaction.Request.Method, interaction.Request.URL))
+	}
+	return errs
+}
+
+// Save writes the recorded interactions in the cassette file
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return errors.New("skalintest: only a recorder in record mode can be saved")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(b, '\n'), 0o644)
+}
+
+// recordRequest returns the request with its secrets redacted.
+// The Authorization header is never recorded
+func recordRequest(req *http.Request) (RecordedRequest, error) {
+	recorded := RecordedRequest{Method: req.Method}
+	u := *req.URL
+	query := u.Query()
+	for _, param := range redactedQueryParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+		}
+	}
+	u.RawQuery = query.Encode()
+	recorded.URL = u.String()
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return recorded, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		recorded.Body = redactJSON(string(body))
+	}
+	return recorded, nil
+}
+
+func (r RecordedRequest) matches(other RecordedRequest) bool {
+	if r.Method != other.Method || r.URL != other.URL {
+		return false
+	}
+	return normalizeJSON(r.Body) == normalizeJSON(other.Body)
+}
+
+// redactJSON replaces the secret fields of a JSON object; other bodies are kept as is
+func redactJSON(body string) string {
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &values); err != nil {
+		return body
+	}
+	changed := false
+	for _, field := range redactedJSONFields {
+		if _, ok := values[field]; ok {
+			values[field] = redacted
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return body
+	}
+	return string(b)
+}
+
+// normalizeJSON makes the comparison of JSON bodies independent of the keys order and spaces
+func normalizeJSON(body string) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return body
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return string(b)
+}
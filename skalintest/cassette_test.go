@@ -0,0 +1,78 @@
+package skalintest_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	_, err := server.Seed(skalintest.Tags, skalinsdk.Tag{Name: "tag1"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	path := filepath.Join(t.TempDir(), "tags.json")
+
+	// record the traffic with the fake server
+	recorder, err := skalintest.NewRecorder(path, skalintest.ModeRecord, server.Client().Transport)
+	if !assert.NoError(t, err) {
+		return
+	}
+	client, err := skalinsdk.New("secretClientId", "secretApiId", "secretApiSecret", skalinsdk.WithHTTPClient(&http.Client{Transport: recorder}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = client.GetTags(nil)
+	if !assert.NoError(t, err) || !assert.NoError(t, recorder.Save()) {
+		return
+	}
+	b, err := os.ReadFile(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	cassette := string(b)
+	for _, secret := range []string{"secretClientId", "secretApiId", "secretApiSecret"} {
+		assert.False(t, strings.Contains(cassette, secret), "%v is in the cassette", secret)
+	}
+	assert.Contains(t, cassette, `\"access_token\":\"REDACTED\"`)
+
+	t.Run("Replay", func(t *testing.T) {
+		recorder, err := skalintest.NewRecorder(path, skalintest.ModeReplay, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		client, err := skalinsdk.New("otherClientId", "otherApiId", "otherApiSecret", skalinsdk.WithHTTPClient(&http.Client{Transport: recorder}))
+		if !assert.NoError(t, err) {
+			return
+		}
+		tags, err := client.GetTags(nil)
+		if !assert.NoError(t, err) || !assert.Len(t, tags, 1) {
+			return
+		}
+		assert.Equal(t, "tag1", tags[0].Name)
+		assert.Empty(t, recorder.Errors())
+	})
+
+	t.Run("Unmatched request", func(t *testing.T) {
+		recorder, err := skalintest.NewRecorder(path, skalintest.ModeReplay, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		client, err := skalinsdk.New("clientId", "apiId", "apiSecret", skalinsdk.WithHTTPClient(&http.Client{Transport: recorder}))
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = client.GetContacts(nil)
+		assert.ErrorContains(t, err, "no recorded interaction")
+		// the unmatched request and the tags request which was not replayed
+		assert.Len(t, recorder.Errors(), 2)
+	})
+}
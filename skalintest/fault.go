@@ -0,0 +1,120 @@
+package skalintest
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fault describes how the server misbehaves for the requests of a route
+type Fault struct {
+	Status         int           // status code returned instead of the normal response (429, 500, 503...)
+	RetryAfter     time.Duration // if set, the Retry-After header is added to the response (in seconds)
+	Body           string        // raw body returned with Status; if empty, an error in the Skalin format is returned
+	Latency        time.Duration // delay before the request is handled
+	DropConnection bool          // the connection is closed without response
+	Times          int           // number of requests affected by the fault, 0 means all the requests
+}
+
+type routeFault struct {
+	method  string
+	pattern string
+	fault   Fault
+	used    int
+}
+
+// InjectFault adds a fault for the requests matching the route.
+// The route is a path pattern (see path.Match), optionally prefixed by a method,
+// like `POST /v1/contacts`, `GET /v1/tags/*`, `/hit` or `/oauth/token`. `*` matches all the requests.
+// When several faults match a request, the first injected one is used until it is exhausted
+func (s *Server) InjectFault(route string, fault Fault) {
+	rf := &routeFault{pattern: route, fault: fault}
+	if method, pattern, found := strings.Cut(route, " "); found {
+		rf.method = strings.ToUpper(method)
+		rf.pattern = strings.TrimSpace(pattern)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, rf)
+}
+
+// ClearFaults removes all the faults, the server behaves normally again
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// ExpireTokens makes all the tokens delivered so far invalid,
+// the API answers 401 until a new token is requested
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token := range s.tokens {
+		s.expiredTokens[token] = true
+	}
+	s.tokens = make(map[string]bool)
+}
+
+func (rf *routeFault) matches(r *http.Request) bool {
+	if rf.fault.Times > 0 && rf.used >= rf.fault.Times {
+		return false
+	}
+	if rf.method != "" && rf.method != r.Method {
+		return false
+	}
+	if rf.pattern == "*" {
+		return true
+	}
+	matched, _ := path.Match(rf.pattern, r.URL.Path)
+	return matched
+}
+
+// nextFault returns the fault to apply to the request, if any
+func (s *Server) nextFault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rf := range s.faults {
+		if rf.matches(r) {
+			rf.used++
+			fault := rf.fault
+			return &fault
+		}
+	}
+	return nil
+}
+
+// applyFault returns true if the request was handled by the fault
+func applyFault(w http.ResponseWriter, r *http.Request, fault *Fault) bool {
+	if fault.Latency > 0 {
+		select {
+		case <-time.After(fault.Latency):
+		case <-r.Context().Done():
+			return true
+		}
+	}
+	if fault.DropConnection {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return true
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+	if fault.Status == 0 {
+		return false
+	}
+	if fault.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Round(time.Second)/time.Second)))
+	}
+	if fault.Body != "" {
+		w.WriteHeader(fault.Status)
+		_, _ = w.Write([]byte(fault.Body))
+		return true
+	}
+	writeError(w, fault.Status, http.StatusText(fault.Status))
+	return true
+}
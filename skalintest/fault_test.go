@@ -0,0 +1,91 @@
+package skalintest_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFaults(t *testing.T) {
+	t.Run("Status for the next calls", func(t *testing.T) {
+		server := skalintest.NewServer()
+		defer server.Close()
+		client := newClient(t, server)
+		server.InjectFault("GET /v1/tags", skalintest.Fault{Status: http.StatusServiceUnavailable, Times: 2})
+
+		_, err := client.GetTags(nil)
+		assert.EqualError(t, err, "Service Unavailable")
+		_, err = client.GetTags(nil)
+		assert.Error(t, err)
+		_, err = client.GetTags(nil)
+		assert.NoError(t, err)
+		// other routes are not affected
+		_, err = client.GetCustomers(nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Retry-After", func(t *testing.T) {
+		server := skalintest.NewServer()
+		defer server.Close()
+		server.InjectFault("/v1/*", skalintest.Fault{Status: http.StatusTooManyRequests, RetryAfter: 2 * time.Second})
+
+		res, err := server.Client().Get(skalinsdk.BuildUrl("/contacts"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		res.Body.Close()
+		assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+		assert.Equal(t, "2", res.Header.Get("Retry-After"))
+	})
+
+	t.Run("Body which is not JSON", func(t *testing.T) {
+		server := skalintest.NewServer()
+		defer server.Close()
+		client := newClient(t, server)
+		server.InjectFault("POST /v1/customers", skalintest.Fault{Status: http.StatusBadGateway, Body: "<html>Bad gateway</html>"})
+
+		_, err := client.SaveCustomer(skalinsdk.Customer{RefId: "customer1"})
+		assert.EqualError(t, err, "<html>Bad gateway</html>")
+	})
+
+	t.Run("Dropped connection", func(t *testing.T) {
+		server := skalintest.NewServer()
+		defer server.Close()
+		server.InjectFault("/oauth/token", skalintest.Fault{DropConnection: true, Times: 1})
+
+		_, err := skalinsdk.New("clientId", "clientApiId", "clientApiSecret", skalinsdk.WithHTTPClient(server.Client()))
+		assert.Error(t, err)
+		_, err = skalinsdk.New("clientId", "clientApiId", "clientApiSecret", skalinsdk.WithHTTPClient(server.Client()))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		server := skalintest.NewServer()
+		defer server.Close()
+		server.InjectFault("*", skalintest.Fault{Latency: 200 * time.Millisecond})
+		httpClient := server.Client()
+		httpClient.Timeout = 50 * time.Millisecond
+
+		_, err := skalinsdk.New("clientId", "clientApiId", "clientApiSecret", skalinsdk.WithHTTPClient(httpClient))
+		assert.Error(t, err)
+		server.ClearFaults()
+		_, err = skalinsdk.New("clientId", "clientApiId", "clientApiSecret", skalinsdk.WithHTTPClient(httpClient))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Expired tokens", func(t *testing.T) {
+		server := skalintest.NewServer()
+		defer server.Close()
+		client := newClient(t, server)
+		server.ExpireTokens()
+
+		_, err := client.GetContacts(nil)
+		assert.EqualError(t, err, "Token expired")
+		_, err = newClient(t, server).GetContacts(nil)
+		assert.NoError(t, err)
+	})
+}
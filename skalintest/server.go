@@ -0,0 +1,455 @@
+// Package skalintest provides an in-memory fake of the Skalin API, to run end-to-end tests
+// of code using the SDK without network access nor Skalin credentials.
+//
+//	server := skalintest.NewServer()
+//	defer server.Close()
+//	client, err := skalinsdk.New("clientId", "clientApiId", "clientApiSecret", skalinsdk.WithHTTPClient(server.Client()))
+//
+// The fake serves the auth, `/v1` REST and collect endpoints, and keeps customers, contacts,
+// agreements and tags in memory. Tags can only be created with Seed, like in Skalin.
+package skalintest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	authHost    = "auth.skalin.io"
+	collectHost = "collect.skalin.io"
+
+	DefaultPageSize = 50
+)
+
+// Hit is a hit received by the collect endpoint
+type Hit struct {
+	Values url.Values
+	Header http.Header
+}
+
+type Server struct {
+	server *httptest.Server
+
+	// if ClientAPIID is set, the auth endpoint only accepts these credentials
+	ClientAPIID     string
+	ClientAPISecret string
+	PageSize        int
+
+	mu            sync.Mutex
+	tokens        map[string]bool
+	expiredTokens map[string]bool
+	stores        map[Kind]*store
+	hits          []Hit
+	faults        []*routeFault
+	sequence      int
+}
+
+func NewServer() *Server {
+	s := &Server{
+		PageSize: DefaultPageSize,
+	}
+	s.Reset()
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL is the base URL of the fake server
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns an HTTP client which sends the requests for the Skalin hosts to the fake server
+func (s *Server) Client() *http.Client {
+	target, _ := url.Parse(s.server.URL)
+	return &http.Client{
+		Transport: &rewriteTransport{target: target, base: s.server.Client().Transport},
+	}
+}
+
+// Reset removes all the entities, hits, tokens and faults of the server
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = make(map[string]bool)
+	s.expiredTokens = make(map[string]bool)
+	s.faults = nil
+	s.stores = make(map[Kind]*store)
+	for _, kind := range kinds {
+		s.stores[kind] = newStore()
+	}
+	s.hits = nil
+}
+
+// Seed adds an entity (like a skalinsdk.Tag or an Entity) to the server and returns its id.
+// If the entity has no id, a new one is generated
+func (s *Server) Seed(kind Kind, value interface{}) (string, error) {
+	entity, err := toEntity(value)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store, ok := s.stores[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown kind %q", kind)
+	}
+	if entity.id() == "" {
+		entity["id"] = s.nextID()
+	}
+	store.put(entity)
+	return entity.id(), nil
+}
+
+// Entities returns a copy of the entities of a kind, in their creation order
+func (s *Server) Entities(kind Kind) []Entity {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store, ok := s.stores[kind]
+	if !ok {
+		return nil
+	}
+	return store.list("", "")
+}
+
+// Entity returns a copy of an entity, or nil if it does not exist
+func (s *Server) Entity(kind Kind, id string) Entity {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store, ok := s.stores[kind]
+	if !ok || store.get(id) == nil {
+		return nil
+	}
+	return copyEntity(store.get(id))
+}
+
+// Hits returns the hits received by the collect endpoint
+func (s *Server) Hits() []Hit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Hit(nil), s.hits...)
+}
+
+func (s *Server) nextID() string {
+	s.sequence++
+	return fmt.Sprintf("%024x", s.sequence)
+}
+
+type rewriteTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+// RoundTrip keeps the original host in the Host header, so the server can route the request
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.Host = req.URL.Host
+	r.URL.Scheme = t.target.Scheme
+	r.URL.Host = t.target.Host
+	return t.base.RoundTrip(r)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if fault := s.nextFault(r); fault != nil && applyFault(w, r, fault) {
+		return
+	}
+	host := r.Host
+	if h, _, found := strings.Cut(host, ":"); found {
+		host = h
+	}
+	switch host {
+	case authHost:
+		s.serveAuth(w, r)
+	case collectHost:
+		s.serveHit(w, r)
+	default:
+		s.serveAPI(w, r)
+	}
+}
+
+type authRequest struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	GrantType    string `json:"grant_type"`
+}
+
+func (s *Server) serveAuth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/oauth/token" {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	var auth authRequest
+	if err := json.NewDecoder(r.Body).Decode(&auth); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+	if auth.GrantType != "client_credentials" ||
+		(s.ClientAPIID != "" && (auth.ClientID != s.ClientAPIID || auth.ClientSecret != s.ClientAPISecret)) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "access_denied", "error_description": "Unauthorized"})
+		return
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	token := hex.EncodeToString(b)
+	s.mu.Lock()
+	s.tokens[token] = true
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   86400,
+	})
+}
+
+func (s *Server) serveHit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/hit" {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if r.Form.Get("client_id") == "" {
+		writeError(w, http.StatusBadRequest, "client_id is required")
+		return
+	}
+	s.mu.Lock()
+	s.hits = append(s.hits, Hit{Values: r.Form, Header: r.Header.Clone()})
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+// authorize returns an error message if the token of the request is not valid
+func (s *Server) authorize(r *http.Request) string {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return "No authorization token was found"
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.expiredTokens[token] {
+		return "Token expired"
+	}
+	if !s.tokens[token] {
+		return "Invalid token"
+	}
+	return ""
+}
+
+func (s *Server) serveAPI(w http.ResponseWriter, r *http.Request) {
+	if message := s.authorize(r); message != "" {
+		writeError(w, http.StatusUnauthorized, message)
+		return
+	}
+	if r.URL.Query().Get("clientId") == "" {
+		writeError(w, http.StatusBadRequest, "clientId is required")
+		return
+	}
+	path, found := strings.CutPrefix(r.URL.Path, "/v1/")
+	if !found {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	kind := Kind(segments[0])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store, ok := s.stores[kind]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		s.list(w, r, store)
+	case len(segments) == 1 && r.Method == http.MethodPost && kind != Tags:
+		s.upsert(w, r, kind, nil)
+	case len(segments) == 2 && r.Method == http.MethodGet:
+		entity := store.get(segments[1])
+		if entity == nil {
+			writeError(w, http.StatusNotFound, notFoundMessage(kind))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "success", "data": entity})
+	case len(segments) == 2 && r.Method == http.MethodPatch && kind != Tags:
+		s.patch(w, r, kind, segments[1])
+	case len(segments) == 2 && r.Method == http.MethodDelete && kind != Tags:
+		if !store.delete(segments[1]) {
+			writeError(w, http.StatusNotFound, notFoundMessage(kind))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "success"})
+	case len(segments) == 3 && r.Method == http.MethodPost && kind == Customers &&
+		(segments[2] == string(Contacts) || segments[2] == string(Agreements)):
+		customer := store.get(segments[1])
+		if customer == nil {
+			writeError(w, http.StatusNotFound, notFoundMessage(Customers))
+			return
+		}
+		s.upsert(w, r, Kind(segments[2]), customer)
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, store *store) {
+	query := r.URL.Query()
+	page, size := 1, s.PageSize
+	if v := query.Get("page"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 1 {
+			writeError(w, http.StatusBadRequest, "page must be a positive integer")
+			return
+		}
+		page = p
+	}
+	if v := query.Get("size"); v != "" {
+		sz, err := strconv.Atoi(v)
+		if err != nil || sz < 1 {
+			writeError(w, http.StatusBadRequest, "size must be a positive integer")
+			return
+		}
+		size = sz
+	}
+	entities := store.list(query.Get("filters"), query.Get("sort"))
+	start := (page - 1) * size
+	if start > len(entities) {
+		start = len(entities)
+	}
+	end := start + size
+	if end > len(entities) {
+		end = len(entities)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data":   entities[start:end],
+		"metadata": map[string]interface{}{
+			"pagination": map[string]interface{}{
+				"size":        size,
+				"page":        page,
+				"total":       len(entities),
+				"hasNextPage": end < len(entities),
+			},
+		},
+	})
+}
+
+// upsert creates the entity, or updates the first entity with the same refId.
+// customer is set for the `/customers/{id}/...` routes
+func (s *Server) upsert(w http.ResponseWriter, r *http.Request, kind Kind, customer Entity) {
+	entity, ok := readEntity(w, r)
+	if !ok {
+		return
+	}
+	delete(entity, "id")
+	if kind == Contacts || kind == Agreements {
+		if customer == nil {
+			customer, ok = s.resolveCustomer(w, entity)
+			if !ok {
+				return
+			}
+		}
+		if customer != nil {
+			entity["customerId"] = customer.id()
+			delete(entity, "customer")
+		}
+	}
+	store := s.stores[kind]
+	if existing := store.findByRefID(entity.refID()); existing != nil {
+		for key, value := range entity {
+			existing[key] = value
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "success", "data": existing})
+		return
+	}
+	entity["id"] = s.nextID()
+	store.put(entity)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "success", "data": entity})
+}
+
+// resolveCustomer returns the customer referenced by `customerId` or by `customer` (the customer refId)
+func (s *Server) resolveCustomer(w http.ResponseWriter, entity Entity) (Entity, bool) {
+	customers := s.stores[Customers]
+	if id, _ := entity["customerId"].(string); id != "" {
+		customer := customers.get(id)
+		if customer == nil {
+			writeError(w, http.StatusNotFound, notFoundMessage(Customers))
+			return nil, false
+		}
+		return customer, true
+	}
+	if refID, _ := entity["customer"].(string); refID != "" {
+		customer := customers.findByRefID(refID)
+		if customer == nil {
+			writeError(w, http.StatusNotFound, notFoundMessage(Customers))
+			return nil, false
+		}
+		return customer, true
+	}
+	return nil, true
+}
+
+func (s *Server) patch(w http.ResponseWriter, r *http.Request, kind Kind, id string) {
+	existing := s.stores[kind].get(id)
+	if existing == nil {
+		writeError(w, http.StatusNotFound, notFoundMessage(kind))
+		return
+	}
+	entity, ok := readEntity(w, r)
+	if !ok {
+		return
+	}
+	delete(entity, "id")
+	for key, value := range entity {
+		existing[key] = value
+	}
+	// like Skalin, the updated entity is not returned
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "success"})
+}
+
+func readEntity(w http.ResponseWriter, r *http.Request) (Entity, bool) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	var entity Entity
+	if err := json.Unmarshal(b, &entity); err != nil || entity == nil {
+		writeError(w, http.StatusBadRequest, "body must be a JSON object")
+		return nil, false
+	}
+	return entity, true
+}
+
+func notFoundMessage(kind Kind) string {
+	return fmt.Sprintf("%v not found", strings.TrimSuffix(string(kind), "s"))
+}
+
+// writeError answers with the error format of the Skalin API
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"status":  "error",
+		"message": message,
+		"code":    statusCode,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,150 @@
+package skalintest_test
+
+import (
+	"testing"
+
+	skalinsdk "github.com/karnott/skalin-sdk"
+	"github.com/karnott/skalin-sdk/skalintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newClient(t *testing.T, server *skalintest.Server) skalinsdk.Skalin {
+	client, err := skalinsdk.New("clientId", "clientApiId", "clientApiSecret", skalinsdk.WithHTTPClient(server.Client()))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return client
+}
+
+func TestServer(t *testing.T) {
+	t.Run("Auth", func(t *testing.T) {
+		server := skalintest.NewServer()
+		defer server.Close()
+		server.ClientAPIID = "clientApiId"
+		server.ClientAPISecret = "otherSecret"
+		_, err := skalinsdk.New("clientId", "clientApiId", "clientApiSecret", skalinsdk.WithHTTPClient(server.Client()))
+		assert.Error(t, err)
+
+		server.ClientAPISecret = "clientApiSecret"
+		_, err = skalinsdk.New("clientId", "clientApiId", "clientApiSecret", skalinsdk.WithHTTPClient(server.Client()))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Customers, contacts and agreements", func(t *testing.T) {
+		server := skalintest.NewServer()
+		defer server.Close()
+		client := newClient(t, server)
+
+		customer, err := client.SaveCustomer(skalinsdk.Customer{
+			RefId:            "customer1",
+			Name:             "Karnott",
+			CustomAttributes: skalinsdk.CustomAttributes{"attribute1": "value1"},
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NotEmpty(t, customer.Id)
+		assert.Equal(t, "value1", server.Entity(skalintest.Customers, customer.Id)["attribute1"])
+
+		// refId upsert
+		updatedCustomer, err := client.SaveCustomer(skalinsdk.Customer{RefId: "customer1", Name: "Karnott SAS"})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, customer.Id, updatedCustomer.Id)
+		assert.Len(t, server.Entities(skalintest.Customers), 1)
+
+		customerRefID := "customer1"
+		contact, err := client.SaveContact(skalinsdk.Contact{RefId: "contact1", Customer: &customerRefID, Email: "contact@karnott.fr"})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, customer.Id, *contact.CustomerId)
+
+		unknownCustomerRefID := "unknown"
+		_, err = client.SaveContact(skalinsdk.Contact{RefId: "contact2", Customer: &unknownCustomerRefID})
+		assert.EqualError(t, err, "customer not found")
+
+		agreement, err := client.CreateAgreementForCustomer(skalinsdk.Agreement{RefId: "agreement1", Plan: "premium"}, customer.Id)
+		if !assert.NoError(t, err) {
+			return
+		}
+		agreement.Plan = "basic"
+		_, err = client.UpdateAgreement(*agreement)
+		if !assert.NoError(t, err) {
+			return
+		}
+		agreements, err := client.GetAgreements(nil)
+		if !assert.NoError(t, err) || !assert.Len(t, agreements, 1) {
+			return
+		}
+		assert.Equal(t, "basic", agreements[0].Plan)
+
+		assert.NoError(t, client.DeleteContact(*contact))
+		assert.Error(t, client.DeleteContact(*contact))
+		assert.Empty(t, server.Entities(skalintest.Contacts))
+	})
+
+	t.Run("Pagination and filters", func(t *testing.T) {
+		server := skalintest.NewServer()
+		defer server.Close()
+		server.PageSize = 2
+		for _, name := range []string{"a", "b", "c", "d", "e"} {
+			_, err := server.Seed(skalintest.Tags, skalinsdk.Tag{Name: name, Entity: skalinsdk.TagEntityContact})
+			if !assert.NoError(t, err) {
+				return
+			}
+		}
+		tagID, err := server.Seed(skalintest.Tags, skalinsdk.Tag{Name: "f", Entity: skalinsdk.TagEntityCustomer})
+		if !assert.NoError(t, err) {
+			return
+		}
+		client := newClient(t, server)
+
+		tags, err := client.GetTags(nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, tags, 6)
+
+		tags, err = client.GetTags(&skalinsdk.GetParams{Filters: map[string]interface{}{"entity": skalinsdk.TagEntityContact}})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, tags, 5)
+
+		tag, err := client.GetTagByID(tagID)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "f", tag.Name)
+
+		_, err = client.GetTagByID("unknown")
+		assert.EqualError(t, err, "tag not found")
+	})
+
+	t.Run("Hits", func(t *testing.T) {
+		server := skalintest.NewServer()
+		defer server.Close()
+		tracker, err := skalinsdk.NewTracker("clientId", skalinsdk.WithHTTPClient(server.Client()))
+		if !assert.NoError(t, err) {
+			return
+		}
+		visitorID := "1234567890123456"
+		_, _, err = tracker.Hit(skalinsdk.HitTrack{
+			Action:    skalinsdk.HitActionUserIdendity,
+			VisitorID: visitorID,
+			VisitID:   visitorID,
+			Identity:  skalinsdk.HitIdentity{ID: &visitorID},
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		hits := server.Hits()
+		if !assert.Len(t, hits, 1) {
+			return
+		}
+		assert.Equal(t, "clientId", hits[0].Values.Get("client_id"))
+		assert.Equal(t, visitorID, hits[0].Values.Get("visitor_id"))
+	})
+}
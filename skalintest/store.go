@@ -0,0 +1,151 @@
+package skalintest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kind is the path of an entity type in the Skalin API
+type Kind string
+
+const (
+	Customers  Kind = "customers"
+	Contacts   Kind = "contacts"
+	Agreements Kind = "agreements"
+	Tags       Kind = "tags"
+)
+
+var kinds = []Kind{Customers, Contacts, Agreements, Tags}
+
+// Entity is an entity as stored by the fake server, with its custom attributes
+type Entity map[string]interface{}
+
+func (e Entity) id() string {
+	id, _ := e["id"].(string)
+	return id
+}
+
+func (e Entity) refID() string {
+	refID, _ := e["refId"].(string)
+	return refID
+}
+
+// store keeps the entities of one kind in their creation order
+type store struct {
+	ids      []string
+	entities map[string]Entity
+}
+
+func newStore() *store {
+	return &store{entities: make(map[string]Entity)}
+}
+
+func (s *store) get(id string) Entity {
+	return s.entities[id]
+}
+
+func (s *store) findByRefID(refID string) Entity {
+	if refID == "" {
+		return nil
+	}
+	// like Skalin, only the first entity with the refId is returned
+	for _, id := range s.ids {
+		if s.entities[id].refID() == refID {
+			return s.entities[id]
+		}
+	}
+	return nil
+}
+
+func (s *store) put(entity Entity) {
+	if _, ok := s.entities[entity.id()]; !ok {
+		s.ids = append(s.ids, entity.id())
+	}
+	s.entities[entity.id()] = entity
+}
+
+func (s *store) delete(id string) bool {
+	if _, ok := s.entities[id]; !ok {
+		return false
+	}
+	delete(s.entities, id)
+	for i, storedID := range s.ids {
+		if storedID == id {
+			s.ids = append(s.ids[:i], s.ids[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// list returns the entities matching all the filters (`key:value` separated by commas),
+// sorted by the `sort` field (prefixed by `-` for descending order)
+func (s *store) list(filters, sortField string) []Entity {
+	conditions := parseFilters(filters)
+	result := make([]Entity, 0, len(s.ids))
+	for _, id := range s.ids {
+		entity := s.entities[id]
+		if entity.matches(conditions) {
+			result = append(result, copyEntity(entity))
+		}
+	}
+	if sortField != "" {
+		descending := strings.HasPrefix(sortField, "-")
+		sortField = strings.TrimPrefix(sortField, "-")
+		sort.SliceStable(result, func(i, j int) bool {
+			a, b := fmt.Sprintf("%v", result[i][sortField]), fmt.Sprintf("%v", result[j][sortField])
+			if descending {
+				return a > b
+			}
+			return a < b
+		})
+	}
+	return result
+}
+
+func parseFilters(filters string) map[string]string {
+	conditions := make(map[string]string)
+	if filters == "" {
+		return conditions
+	}
+	for _, filter := range strings.Split(filters, ",") {
+		key, value, _ := strings.Cut(filter, ":")
+		conditions[key] = value
+	}
+	return conditions
+}
+
+func (e Entity) matches(conditions map[string]string) bool {
+	for key, value := range conditions {
+		if fmt.Sprintf("%v", e[key]) != value {
+			return false
+		}
+	}
+	return true
+}
+
+func copyEntity(e Entity) Entity {
+	c := make(Entity, len(e))
+	for key, value := range e {
+		c[key] = value
+	}
+	return c
+}
+
+// toEntity converts any JSON serializable value (like skalinsdk.Contact) into an Entity
+func toEntity(value interface{}) (Entity, error) {
+	if entity, ok := value.(Entity); ok {
+		return copyEntity(entity), nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var entity Entity
+	if err := json.Unmarshal(b, &entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
@@ -3,11 +3,11 @@ package skalinsdk
 import "fmt"
 
 type Tag struct {
-	Id     string `json:"id"`
-	Name   string `json:"name"`
-	Type   string `json:"type"`
-	Entity string `json:"entity"`
-	Color  string `json:"color"`
+	Id     string    `json:"id"`
+	Name   string    `json:"name"`
+	Type   TagType   `json:"type"`
+	Entity TagEntity `json:"entity"`
+	Color  string    `json:"color"`
 }
 
 const (
@@ -19,6 +19,11 @@ func (s *skalinAPI) GetTags(params *GetParams) ([]Tag, error) {
 	return getEntities[[]Tag](s, GET_TAGS, buildQueryParamsFromGetParams(params))
 }
 
+// WalkTags calls fn with the tags of each page, it stops at the first error returned by fn
+func (s *skalinAPI) WalkTags(params *GetParams, fn func([]Tag) error) error {
+	return walkEntities[[]Tag](s, GET_TAGS, buildQueryParamsFromGetParams(params), fn)
+}
+
 func (s *skalinAPI) GetTagByID(id string) (*Tag, error) {
 	return getEntity[Tag](s, fmt.Sprintf(GET_TAG_BY_ID, id), nil)
 }
@@ -2,10 +2,12 @@ package skalinsdk
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
 
+	"github.com/karnott/skalin-sdk/skalintest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -101,6 +103,19 @@ func TestGetTags(t *testing.T) {
 			assert.NotEqual(t, "", tag.Name)
 		}
 	})
+
+	t.Run("Replay", func(t *testing.T) {
+		skalinApi, err := newCassetteClient(t, "get_tags")
+		if !assert.NoError(t, err) {
+			return
+		}
+		tags, err := skalinApi.GetTags(nil)
+		if !assert.NoError(t, err) || !assert.Len(t, tags, 3) {
+			return
+		}
+		assert.Equal(t, "Viticulture", tags[0].Name)
+		assert.Equal(t, TagEntityContact, tags[2].Entity)
+	})
 }
 
 func TestGetTagByID(t *testing.T) {
@@ -198,3 +213,37 @@ func TestGetTagByID(t *testing.T) {
 		}
 	})
 }
+
+func TestWalkTags(t *testing.T) {
+	server := skalintest.NewServer()
+	defer server.Close()
+	for _, name := range []string{"1", "2", "3"} {
+		_, err := server.Seed(skalintest.Tags, skalintest.Entity{"name": name})
+		assert.NoError(t, err)
+	}
+	skalinApi, err := New("clientId", "clientApiId", "clientApiSecret", WithHTTPClient(server.Client()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	size := 2
+
+	t.Run("OK", func(t *testing.T) {
+		pages := make([]int, 0)
+		err := skalinApi.WalkTags(&GetParams{Size: &size}, func(tags []Tag) error {
+			pages = append(pages, len(tags))
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2, 1}, pages)
+	})
+
+	t.Run("With error", func(t *testing.T) {
+		calls := 0
+		err := skalinApi.WalkTags(&GetParams{Size: &size}, func(tags []Tag) error {
+			calls++
+			return errors.New("stop")
+		})
+		assert.EqualError(t, err, "stop")
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -2,10 +2,14 @@ package skalinsdk
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
+	"reflect"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -20,9 +24,21 @@ type HitIdentity struct {
 	Email *string `json:"email,omitempty" validate:"required_without=ID,omitempty,email"`
 }
 
+// limits of the properties of an event, checked by the `event_properties` validation
+const (
+	MaxEventProperties          = 50
+	MaxEventPropertyKeyLength   = 64
+	MaxEventPropertyValueLength = 255
+)
+
+// EventProperties is the context attached to an event (feature id, plan, value, device...).
+// Values can only be strings, booleans or numbers
+type EventProperties map[string]interface{}
+
 type HitEvent struct {
-	Name      string `json:"name" validate:"required"`
-	EventName string `json:"event_name" validate:"required"`
+	Name       string          `json:"name" validate:"required"`
+	EventName  string          `json:"event_name" validate:"required"`
+	Properties EventProperties `json:"properties,omitempty" validate:"omitempty,event_properties"`
 }
 
 type HitTrack struct {
@@ -34,22 +50,108 @@ type HitTrack struct {
 	EventID       *string     `validate:"omitempty,len=16"`
 	CustomerID    *string
 	Ts            *time.Time
+	Location      *time.Location // timezone of the end user, used to compute `localtime`
+	TimeZone      string         `validate:"omitempty,timezone"` // IANA name of the end user timezone, used if Location is not set
 	URL           *string
 	CIP           *string // client ip
 	CustomHeaders map[string][]string
 }
 
+// location returns the timezone of the end user.
+// If neither Location nor TimeZone is set, the timezone of the server is used
+func (ht HitTrack) location() (*time.Location, error) {
+	if ht.Location != nil {
+		return ht.Location, nil
+	}
+	if ht.TimeZone != "" {
+		return time.LoadLocation(ht.TimeZone)
+	}
+	return time.Local, nil
+}
+
+var hitValidator = newHitValidator()
+
+func newHitValidator() *validator.Validate {
+	v := validator.New()
+	// the error can only happen if the tag is empty or already used by the validator
+	_ = v.RegisterValidation("event_properties", validateEventProperties)
+	return v
+}
+
+func validateEventProperties(fl validator.FieldLevel) bool {
+	properties, ok := fl.Field().Interface().(EventProperties)
+	if !ok || len(properties) > MaxEventProperties {
+		return false
+	}
+	for key, value := range properties {
+		if key == "" || utf8.RuneCountInString(key) > MaxEventPropertyKeyLength || !validEventPropertyValue(reflect.ValueOf(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func validEventPropertyValue(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(field.String()) <= MaxEventPropertyValueLength
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Float32, reflect.Float64:
+		// NaN and infinity can not be marshalled in JSON
+		return !math.IsNaN(field.Float()) && !math.IsInf(field.Float(), 0)
+	}
+	return false
+}
+
+func validateHit(ht HitTrack) error {
+	return hitValidator.Struct(ht)
+}
+
 func (a skalinTracker) Hit(ht HitTrack) (*http.Response, []byte, error) {
-	validator := validator.New()
-	err := validator.Struct(ht)
+	a.hitQueued(1)
+	err := validateHit(ht)
 	if err != nil {
+		a.hitDone(HitDropped, 0)
 		return nil, nil, err
 	}
+	res, body, err := a.sendHit(ht.WithEventID())
+	switch {
+	case errors.Is(err, ErrDuplicateHit):
+		a.hitDone(HitDropped, 1)
+	case err != nil:
+		a.hitDone(HitFailed, 1)
+	default:
+		a.hitDone(HitSent, 1)
+	}
+	return res, body, err
+}
 
+// sendHit posts an already validated hit to the collect endpoint.
+// The hit is not sent if a hit with the same event id was already sent by the tracker
+func (a skalinTracker) sendHit(ht HitTrack) (*http.Response, []byte, error) {
 	if a.api.GetClientID() == nil {
 		return nil, nil, fmt.Errorf("client_id is not set")
 	}
+	if ht.EventID != nil {
+		if !a.dedup.reserve(*ht.EventID) {
+			return nil, nil, ErrDuplicateHit
+		}
+	}
+	res, body, err := a.postHit(ht)
+	if ht.EventID != nil {
+		if err != nil {
+			a.dedup.release(*ht.EventID)
+		} else {
+			a.dedup.confirm(*ht.EventID)
+		}
+	}
+	return res, body, err
+}
 
+func (a skalinTracker) postHit(ht HitTrack) (*http.Response, []byte, error) {
 	data := url.Values{}
 	data.Set("rec", "1")
 	data.Set("action", string(ht.Action))
@@ -81,14 +183,16 @@ func (a skalinTracker) Hit(ht HitTrack) (*http.Response, []byte, error) {
 	}
 
 	if ht.Ts != nil {
-		data.Set("localtime", ht.Ts.Local().Format(time.TimeOnly))
+		location, err := ht.location()
+		if err != nil {
+			return nil, nil, err
+		}
+		data.Set("localtime", ht.Ts.In(location).Format(time.TimeOnly))
+		data.Set("ts", ht.Ts.UTC().Format("2006-01-02T15:04:05"))
 	}
 
 	if ht.URL != nil {
 		data.Set("url", *ht.URL)
-		if ht.Action == HitActionEvent && ht.Ts != nil {
-			data.Set("ts", ht.Ts.UTC().Format("2006-01-02T15:04:05"))
-		}
 	}
 
 	if ht.CIP != nil {
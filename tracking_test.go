@@ -1,8 +1,13 @@
 package skalinsdk
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -41,3 +46,165 @@ func TestHit(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestHitTimezone(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if !assert.NoError(t, err) {
+		return
+	}
+	testCases := []struct {
+		name              string
+		ts                time.Time
+		location          *time.Location
+		timezone          string
+		expectedLocaltime string
+		expectedTs        string
+	}{
+		{"Before spring DST", time.Date(2023, 3, 26, 0, 30, 0, 0, time.UTC), paris, "", "01:30:00", "2023-03-26T00:30:00"},
+		{"After spring DST", time.Date(2023, 3, 26, 1, 30, 0, 0, time.UTC), paris, "", "03:30:00", "2023-03-26T01:30:00"},
+		{"Before autumn DST", time.Date(2023, 10, 29, 0, 30, 0, 0, time.UTC), nil, "Europe/Paris", "02:30:00", "2023-10-29T00:30:00"},
+		{"After autumn DST", time.Date(2023, 10, 29, 1, 30, 0, 0, time.UTC), nil, "Europe/Paris", "02:30:00", "2023-10-29T01:30:00"},
+		{"Overseas", time.Date(2023, 10, 29, 1, 30, 0, 0, time.UTC), nil, "Indian/Reunion", "05:30:00", "2023-10-29T01:30:00"},
+		{"Location has priority", time.Date(2023, 7, 14, 12, 0, 0, 0, time.UTC), time.UTC, "Europe/Paris", "12:00:00", "2023-07-14T12:00:00"},
+	}
+	for _, tc := range testCases {
+		for _, action := range []HitAction{HitActionEvent, HitActionUserIdendity} {
+			t.Run(tc.name+" "+string(action), func(t *testing.T) {
+				mockApi := new(MockAPI)
+				mockApi.On(
+					"send",
+					http.MethodPost,
+					SKALIN_HIT_URL,
+					formURLEncodedContentType,
+					mock.Anything,
+					mock.Anything,
+					mock.Anything,
+					http.StatusOK,
+				).Return(nil, nil, nil)
+
+				ht := HitTrack{
+					Action:    action,
+					VisitorID: "1234567890123456",
+					VisitID:   "1234567890123456",
+					Identity: HitIdentity{
+						ID: sPtr("test"),
+					},
+					Ts:       &tc.ts,
+					Location: tc.location,
+					TimeZone: tc.timezone,
+				}
+				if action == HitActionEvent {
+					ht.Event = &HitEvent{Name: "test", EventName: "test"}
+				}
+				skalinTracker := &skalinTracker{api: mockApi}
+				_, _, err := skalinTracker.Hit(ht)
+				if !assert.NoError(t, err) {
+					return
+				}
+				data := mockApi.Calls[0].Arguments.Get(5).(*url.Values)
+				assert.Equal(t, tc.expectedLocaltime, data.Get("localtime"))
+				assert.Equal(t, tc.expectedTs, data.Get("ts"))
+			})
+		}
+	}
+
+	t.Run("With invalid timezone", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		ts := time.Now()
+		skalinTracker := &skalinTracker{api: mockApi}
+		_, _, err := skalinTracker.Hit(HitTrack{
+			Action:    HitActionUserIdendity,
+			VisitorID: "1234567890123456",
+			VisitID:   "1234567890123456",
+			Identity: HitIdentity{
+				ID: sPtr("test"),
+			},
+			Ts:       &ts,
+			TimeZone: "Europe/Karnott",
+		})
+		assert.Error(t, err)
+		mockApi.AssertNotCalled(t, "send")
+	})
+}
+
+func TestHitEventProperties(t *testing.T) {
+	newHit := func(properties EventProperties) HitTrack {
+		return HitTrack{
+			Action:    HitActionEvent,
+			VisitorID: "1234567890123456",
+			VisitID:   "1234567890123456",
+			Identity: HitIdentity{
+				ID: sPtr("test"),
+			},
+			Event: &HitEvent{
+				Name:       "test",
+				EventName:  "test",
+				Properties: properties,
+			},
+		}
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		mockApi := new(MockAPI)
+		mockApi.On(
+			"send",
+			http.MethodPost,
+			SKALIN_HIT_URL,
+			formURLEncodedContentType,
+			mock.Anything,
+			mock.Anything,
+			mock.Anything,
+			http.StatusOK,
+		).Return(nil, nil, nil)
+
+		skalinTracker := &skalinTracker{api: mockApi}
+		_, _, err := skalinTracker.Hit(newHit(EventProperties{
+			"feature_id": "map",
+			"plan":       "premium",
+			"value":      12.5,
+			"count":      3,
+			"mobile":     true,
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+		data := mockApi.Calls[0].Arguments.Get(5).(*url.Values)
+		assert.JSONEq(
+			t,
+			`{"name": "test", "event_name": "test", "properties": {"feature_id": "map", "plan": "premium", "value": 12.5, "count": 3, "mobile": true}}`,
+			data.Get("event"),
+		)
+	})
+
+	t.Run("At the limits", func(t *testing.T) {
+		properties := EventProperties{strings.Repeat("k", MaxEventPropertyKeyLength): strings.Repeat("v", MaxEventPropertyValueLength)}
+		for i := 1; i < MaxEventProperties; i++ {
+			properties[fmt.Sprintf("property%v", i)] = i
+		}
+		assert.NoError(t, validateHit(newHit(properties)))
+	})
+
+	tooManyProperties := EventProperties{}
+	for i := 0; i <= MaxEventProperties; i++ {
+		tooManyProperties[fmt.Sprintf("property%v", i)] = i
+	}
+	invalidProperties := map[string]EventProperties{
+		"Too many properties": tooManyProperties,
+		"Key too long":        {strings.Repeat("k", MaxEventPropertyKeyLength+1): "value"},
+		"Empty key":           {"": "value"},
+		"Value too long":      {"key": strings.Repeat("v", MaxEventPropertyValueLength+1)},
+		"Nested value":        {"key": map[string]string{"nested": "value"}},
+		"Slice value":         {"key": []string{"value"}},
+		"Nil value":           {"key": nil},
+		"NaN value":           {"key": math.NaN()},
+	}
+	for name, properties := range invalidProperties {
+		t.Run(name, func(t *testing.T) {
+			mockApi := new(MockAPI)
+			skalinTracker := &skalinTracker{api: mockApi}
+			_, _, err := skalinTracker.Hit(newHit(properties))
+			assert.Error(t, err)
+			mockApi.AssertNotCalled(t, "send")
+		})
+	}
+}